@@ -5,7 +5,6 @@ import (
 	"Scout.go/log"
 	"Scout.go/models"
 	"Scout.go/reg"
-	"Scout.go/storage"
 	"Scout.go/util"
 	"fmt"
 	"github.com/go-mysql-org/go-mysql/canal"
@@ -21,23 +20,30 @@ import (
 	"time"
 )
 
+// retryDelay is how long changes that could not be applied wait before they are tried again
+const retryDelay = 5 * time.Second
+
 type Maker struct {
 	MakerInterface
 
 	changes          []*canal.RowsEvent
 	debouncedChannel chan *CanalEvent
 	changesMu        sync.Mutex
-	index            *storage.Index
+
+	// position is where the binlog was synced to after the pending changes, saved once they are applied
+	position *models.BinlogPosition
 }
 
 type CanalEvent struct {
-	Status string
-	ID     int32
-	Event  *canal.RowsEvent
+	Status   string
+	ID       int32
+	Event    *canal.RowsEvent
+	Position *models.BinlogPosition
 }
 
 func NewMaker(cnf *models.DbConfig) *Maker {
-	searchIndex, err := reg.IndexByName(cnf.Index)
+	// the index is resolved again on every write, so an alias swap redirects the watcher
+	_, err := reg.WriteIndex(cnf.Index)
 	if err != nil {
 		log.AppLog.E(cnf.Index, "watching data changes but no index found", zap.Error(err))
 	}
@@ -45,7 +51,6 @@ func NewMaker(cnf *models.DbConfig) *Maker {
 		changes:          make([]*canal.RowsEvent, 0),
 		debouncedChannel: nil,
 		changesMu:        sync.Mutex{},
-		index:            searchIndex,
 	}
 	i.DbCnf = cnf
 	i.EventChannel = nil
@@ -99,25 +104,41 @@ func (b *Maker) Start() {
 	b.debouncedChannel = b.debounce(100*time.Millisecond, 1*time.Second, b.EventChannel)
 	defer close(b.debouncedChannel)
 
+	// changes that could not be applied are tried again, also when no new event arrives
+	var retry <-chan time.Time
 OUTER:
 	for {
 		select {
 		case <-b.Done:
 			break OUTER
+		case <-retry:
+			retry = nil
+			if !b.processData() {
+				retry = time.After(retryDelay)
+			}
 		case event := <-b.debouncedChannel:
 			if event == nil {
 				break
 			}
-			if event.Status == "start" || event.Status == "stop" {
-				b.processData()
+			if event.Status == "start" || event.Status == "stop" || event.Status == "synced" {
+				if !b.processData() {
+					retry = time.After(retryDelay)
+				}
 			}
 		}
 	}
 	time.Sleep(100 * time.Millisecond)
 }
 
-func (b *Maker) processData() {
+// processData applies the changes collected since the last run, the binlog position that follows them is
+// saved only when they were applied. Runs are debounced, so the position is written at most once per run.
+// Changes that failed stay pending with their position and go out again with the next run.
+func (b *Maker) processData() bool {
 	b.changesMu.Lock()
+	defer b.changesMu.Unlock()
+	position := b.position
+	b.position = nil
+	applied := true
 	if b.changes != nil && len(b.changes) > 0 {
 		changes := util.Map(b.changes, func(e *canal.RowsEvent) []map[string]interface{} {
 			v := make([]map[string]interface{}, 0)
@@ -127,6 +148,12 @@ func (b *Maker) processData() {
 			for _, r := range e.Rows {
 				row := make(map[string]interface{})
 				for i, col := range r {
+					// JSON columns arrive as text, decode them so nested paths can be indexed
+					if e.Table.Columns[i].Type == schema.TYPE_JSON {
+						if parsed, ok := util.ParseJSON(col); ok {
+							col = parsed
+						}
+					}
 					row[columns[i]] = col
 				}
 				v = append(v, row)
@@ -139,13 +166,22 @@ func (b *Maker) processData() {
 				dataToPost = append(dataToPost, row)
 			}
 		}
-		b.followUserProtocol(dataToPost)
-		b.changes = nil
+		applied = b.followUserProtocol(dataToPost) == nil
+	}
+	if !applied {
+		b.position = position
+		return false
+	}
+	b.changes = nil
+	if position != nil {
+		if err := internal.DB.PutMap(b.DbCnf.Index, position, internal.BinlogStore); err != nil {
+			log.AppLog.E(b.DbCnf.Index, "error putting binlog position", zap.Error(err))
+		}
 	}
-	b.changesMu.Unlock()
+	return true
 }
 
-func (b *Maker) followUserProtocol(dataToPost []map[string]interface{}) {
+func (b *Maker) followUserProtocol(dataToPost []map[string]interface{}) error {
 	client := resty.New().R()
 	if b.DbCnf.MakerHeaders != nil && len(b.DbCnf.MakerHeaders) > 0 {
 		for _, header := range b.DbCnf.MakerHeaders {
@@ -158,18 +194,26 @@ func (b *Maker) followUserProtocol(dataToPost []map[string]interface{}) {
 		response, err := client.Post(b.DbCnf.MakerHook)
 		if err != nil {
 			log.AppLog.E(b.DbCnf.Index, "maker hook error", zap.Error(err))
+			return err
 		}
 		log.AppLog.Info("maker hook response", zap.Any("response", response))
-	} else {
-		if b.index != nil {
-			err := b.index.PrepareAndIndex(dataToPost)
-			if err != nil {
-				log.AppLog.E(b.DbCnf.Index, "prepare index error", zap.Error(err))
-			}
-		} else {
-			log.AppLog.E(b.DbCnf.Index, "prepare data to index (nil)")
+		if response.IsError() {
+			err := fmt.Errorf("maker hook responded %s", response.Status())
+			log.AppLog.E(b.DbCnf.Index, "maker hook error", zap.Error(err))
+			return err
 		}
+		return nil
+	}
+	index, err := reg.WriteIndex(b.DbCnf.Index)
+	if err != nil {
+		log.AppLog.E(b.DbCnf.Index, "prepare data to index (nil)", zap.Error(err))
+		return err
+	}
+	if _, err := index.PrepareAndIndex(dataToPost); err != nil {
+		log.AppLog.E(b.DbCnf.Index, "prepare index error", zap.Error(err))
+		return err
 	}
+	return nil
 }
 
 func (b *Maker) debounce(min time.Duration, max time.Duration, input chan *CanalEvent) chan *CanalEvent {
@@ -190,11 +234,14 @@ func (b *Maker) debounce(min time.Duration, max time.Duration, input chan *Canal
 				if !ok {
 					return
 				}
+				b.changesMu.Lock()
 				if buffer.Event != nil {
-					b.changesMu.Lock()
 					b.changes = append(b.changes, buffer.Event)
-					b.changesMu.Unlock()
 				}
+				if buffer.Position != nil {
+					b.position = buffer.Position
+				}
+				b.changesMu.Unlock()
 				minTimer = time.After(min)
 				if maxTimer == nil {
 					maxTimer = time.After(max)
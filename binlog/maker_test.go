@@ -0,0 +1,81 @@
+package binlog
+
+import (
+	"Scout.go/internal"
+	"Scout.go/models"
+	"encoding/json"
+	"github.com/go-mysql-org/go-mysql/canal"
+	"github.com/go-mysql-org/go-mysql/schema"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestMain keeps the config store in a temporary working directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "scout-binlog")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	internal.NewDiskStorage()
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestProcessDataKeepsFailedChanges(t *testing.T) {
+	failing := true
+	posted := make([][]map[string]interface{}, 0)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rows []map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&rows)
+		posted = append(posted, rows)
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer hook.Close()
+
+	maker := NewMaker(&models.DbConfig{Index: "retried", MakerHook: hook.URL})
+	t.Cleanup(func() { _ = internal.DB.Delete("retried", internal.BinlogStore) })
+	table := &schema.Table{Columns: []schema.TableColumn{{Name: "id"}, {Name: "title"}}}
+	maker.changes = []*canal.RowsEvent{{Table: table, Action: canal.InsertAction, Rows: [][]interface{}{{"1", "shoe"}}}}
+	maker.position = &models.BinlogPosition{Name: "mysql-bin.000001", Pos: 42}
+	saved := func() *models.BinlogPosition {
+		var position models.BinlogPosition
+		if err := internal.DB.GetMap("retried", &position, internal.BinlogStore); err != nil {
+			return nil
+		}
+		return &position
+	}
+
+	if maker.processData() {
+		t.Fatal("changes the hook refused were reported as applied")
+	}
+	if position := saved(); position != nil {
+		t.Errorf("the position moved on to %v past changes that were not applied", position)
+	}
+	if len(maker.changes) != 1 || maker.position == nil {
+		t.Fatalf("failed changes were dropped: %v, position %v", maker.changes, maker.position)
+	}
+
+	failing = false
+	if !maker.processData() {
+		t.Fatal("the retry was not applied")
+	}
+	if position := saved(); position == nil || position.Pos != 42 {
+		t.Errorf("saved position = %v, want 42", position)
+	}
+	want := []map[string]interface{}{{"id": "1", "title": "shoe"}}
+	if len(posted) != 2 || !reflect.DeepEqual(posted[1], want) {
+		t.Errorf("posted = %v, want the row twice", posted)
+	}
+	if len(maker.changes) != 0 || maker.position != nil {
+		t.Errorf("applied changes stayed pending: %v, position %v", maker.changes, maker.position)
+	}
+}
@@ -21,6 +21,16 @@ import (
 type Watchman struct {
 	Canal   *canal.Canal
 	Handler *ScoutMySqlEventHandler
+
+	host    string
+	quit    chan struct{}
+	stopped chan struct{}
+}
+
+// Stop ends the rotation monitor and waits until it closed the binlog connection and stopped the maker.
+func (w *Watchman) Stop() {
+	close(w.quit)
+	<-w.stopped
 }
 
 type Service struct {
@@ -62,19 +72,34 @@ func (a *Service) ListenForNewHost(ch chan interface{}) {
 		case *models.DbConfig:
 			log.AppLog.Info("requesting a new watchman", zap.String("index", v.Index))
 			w, ok := a.Warehouse[v.Index]
-			if ok {
+			if ok && w != nil {
 				log.AppLog.Warn("existing watchman found. closing connection", zap.String("index", v.Index))
-				w.Canal.Close()
-				w.Handler.Stop()
+				a.dismiss(v.Index, w)
 				time.Sleep(5 * time.Second)
 			}
 			a.AssignNewWatchman(v)
+		case *models.IndexRemoved:
+			w, ok := a.Warehouse[v.Index]
+			if ok && w != nil {
+				log.AppLog.Info("index removed. closing watchman", zap.String("index", v.Index))
+				a.dismiss(v.Index, w)
+			}
+			delete(a.Warehouse, v.Index)
 		default:
 			log.AppLog.Error("unexpected message type", zap.Any("msg", msg))
 		}
 	}
 }
 
+// dismiss stops the watchman and frees its host so a later config can connect to it again.
+func (a *Service) dismiss(index string, w *Watchman) {
+	w.Stop()
+	delete(a.Warehouse, index)
+	a.servers = slices.DeleteFunc(a.servers, func(host string) bool {
+		return host == w.host
+	})
+}
+
 func getMasterStatus(dbCfg *models.DbConfig) (string, uint32, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/", dbCfg.User, dbCfg.Password, dbCfg.Host, dbCfg.SafePort())
 	db, err := sql.Open("mysql", dsn)
@@ -137,6 +162,9 @@ func (a *Service) GetWatchman(dbCfg *models.DbConfig) (*Watchman, error) {
 		w := Watchman{
 			Canal:   c,
 			Handler: NewScoutMySqlEventHandler(m),
+			host:    dbCfg.Host,
+			quit:    make(chan struct{}),
+			stopped: make(chan struct{}),
 		}
 		c.SetEventHandler(w.Handler)
 
@@ -149,7 +177,7 @@ func (a *Service) GetWatchman(dbCfg *models.DbConfig) (*Watchman, error) {
 			return nil, nil
 		}
 		startPos := mysql.Position{Name: file, Pos: pos}
-		go a.monitorBinlogChanges(c, dbCfg, startPos, cfg)
+		go a.monitorBinlogChanges(&w, dbCfg, startPos, cfg)
 
 		return &w, nil
 	}
@@ -157,11 +185,20 @@ func (a *Service) GetWatchman(dbCfg *models.DbConfig) (*Watchman, error) {
 }
 
 // monitorBinlogChanges monitors changes in the binlog file and position, and restarts the Canal instance if necessary.
-func (a *Service) monitorBinlogChanges(c *canal.Canal, dbCfg *models.DbConfig, startPos mysql.Position, cfg *canal.Config) {
+// It owns the canal and the handler of the watchman, a replaced pair is closed together with its maker.
+func (a *Service) monitorBinlogChanges(w *Watchman, dbCfg *models.DbConfig, startPos mysql.Position, cfg *canal.Config) {
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
+	defer close(w.stopped)
+
+	for {
+		select {
+		case <-w.quit:
+			w.release()
+			return
+		case <-ticker.C:
+		}
 
-	for range ticker.C {
 		currentFile, currentPos, err := getMasterStatus(dbCfg)
 		if err != nil {
 			log.CanalLog.Error("error getting master status on rotate", zap.Error(err))
@@ -172,17 +209,28 @@ func (a *Service) monitorBinlogChanges(c *canal.Canal, dbCfg *models.DbConfig, s
 			startPos.Name = currentFile
 			startPos.Pos = currentPos
 
-			if c != nil {
-				c.Close()
-			}
-			c, err = canal.NewCanal(cfg) // Recreate the canal instance
+			w.release()
+			c, err := canal.NewCanal(cfg) // Recreate the canal instance
 			if err != nil {
 				log.CanalLog.Error("error creating canal on rotate", zap.Error(err))
 				continue
 			}
 
-			c.SetEventHandler(NewScoutMySqlEventHandler(NewMaker(dbCfg)))
+			w.Canal, w.Handler = c, NewScoutMySqlEventHandler(NewMaker(dbCfg))
+			c.SetEventHandler(w.Handler)
 			go c.RunFrom(startPos)
 		}
 	}
 }
+
+// release closes the canal before stopping its maker, so no event reaches a stopped maker.
+func (w *Watchman) release() {
+	if w.Canal != nil {
+		w.Canal.Close()
+		w.Canal = nil
+	}
+	if w.Handler != nil {
+		w.Handler.Stop()
+		w.Handler = nil
+	}
+}
@@ -2,6 +2,7 @@ package binlog
 
 import (
 	"Scout.go/internal"
+	"Scout.go/models"
 	"fmt"
 	"github.com/go-mysql-org/go-mysql/canal"
 	"github.com/go-mysql-org/go-mysql/mysql"
@@ -10,6 +11,7 @@ import (
 	"golang.org/x/exp/slices"
 	"math/rand"
 	"strings"
+	"time"
 )
 
 type ScoutMySqlEventHandler struct {
@@ -30,7 +32,16 @@ func (h *ScoutMySqlEventHandler) OnRow(e *canal.RowsEvent) error {
 	return nil
 }
 
+// OnPosSynced hands the position canal has handled the binlog up to to the maker. It follows the rows of
+// the transaction through the same channel, the maker saves it once those rows are indexed.
 func (h *ScoutMySqlEventHandler) OnPosSynced(header *replication.EventHeader, pos mysql.Position, set mysql.GTIDSet, force bool) error {
+	if h.maker == nil || pos.Name == "" {
+		return nil
+	}
+	h.maker.EventChannel <- &CanalEvent{
+		Status:   "synced",
+		Position: &models.BinlogPosition{Name: pos.Name, Pos: pos.Pos, SyncedAt: time.Now()},
+	}
 	return nil
 }
 
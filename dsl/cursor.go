@@ -0,0 +1,66 @@
+package dsl
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/blevesearch/bleve/v2/search"
+	"strconv"
+)
+
+const (
+	cursorAfter  = "after"
+	cursorBefore = "before"
+)
+
+type cursor struct {
+	Direction string   `json:"d"`
+	Sort      []string `json:"s"`
+}
+
+// NextCursor points past the given hit sort values.
+func NextCursor(sort []string) string {
+	return encodeCursor(cursorAfter, sort)
+}
+
+// PrevCursor points before the given hit sort values.
+func PrevCursor(sort []string) string {
+	return encodeCursor(cursorBefore, sort)
+}
+
+// CursorKeys are the sort values of the hit as search_after reads them back. bleve reports a score key
+// as the literal "_score", the cursor needs the score itself to continue after the hit.
+func CursorKeys(hit *search.DocumentMatch, order search.SortOrder) []string {
+	keys := append([]string(nil), hit.Sort...)
+	for n, sort := range order {
+		if _, ok := sort.(*search.SortScore); ok && n < len(keys) {
+			keys[n] = strconv.FormatFloat(hit.Score, 'g', -1, 64)
+		}
+	}
+	return keys
+}
+
+// DecodeCursor returns the search_after or search_before keys carried by a cursor token.
+func DecodeCursor(token string) (after []string, before []string, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid cursor")
+	}
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil || len(c.Sort) == 0 {
+		return nil, nil, fmt.Errorf("invalid cursor")
+	}
+	switch c.Direction {
+	case cursorAfter:
+		return c.Sort, nil, nil
+	case cursorBefore:
+		return nil, c.Sort, nil
+	default:
+		return nil, nil, fmt.Errorf("invalid cursor")
+	}
+}
+
+func encodeCursor(direction string, sort []string) string {
+	raw, _ := json.Marshal(cursor{Direction: direction, Sort: sort})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
@@ -0,0 +1,40 @@
+package dsl
+
+import (
+	"github.com/blevesearch/bleve/v2/search"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestCursorKeysCarryTheScore(t *testing.T) {
+	hit := &search.DocumentMatch{ID: "p1", Score: 0.7234, Sort: []string{"_score", "shoe", "p1"}}
+	order := search.SortOrder{&search.SortScore{Desc: true}, &search.SortField{Field: "title"}, &search.SortDocID{}}
+
+	keys := CursorKeys(hit, order)
+	if want := []string{"0.7234", "shoe", "p1"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("CursorKeys() = %v, want %v", keys, want)
+	}
+	if score, err := strconv.ParseFloat(keys[0], 64); err != nil || score != hit.Score {
+		t.Errorf("score key %q does not read back as %v", keys[0], hit.Score)
+	}
+	if hit.Sort[0] != "_score" {
+		t.Errorf("CursorKeys() changed the hit sort values to %v", hit.Sort)
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	after, before, err := DecodeCursor(NextCursor([]string{"0.5", "p1"}))
+	if err != nil || !reflect.DeepEqual(after, []string{"0.5", "p1"}) || before != nil {
+		t.Errorf("next cursor decoded to %v, %v, %v", after, before, err)
+	}
+	after, before, err = DecodeCursor(PrevCursor([]string{"p1"}))
+	if err != nil || after != nil || !reflect.DeepEqual(before, []string{"p1"}) {
+		t.Errorf("prev cursor decoded to %v, %v, %v", after, before, err)
+	}
+	for _, token := range []string{"%%%", NextCursor(nil), encodeCursor("sideways", []string{"p1"})} {
+		if _, _, err := DecodeCursor(token); err == nil {
+			t.Errorf("cursor %q was accepted", token)
+		}
+	}
+}
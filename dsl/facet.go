@@ -0,0 +1,137 @@
+package dsl
+
+import (
+	"Scout.go/models"
+	"fmt"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search"
+	"time"
+)
+
+const (
+	defaultFacetSize     = 10
+	maxHistogramBuckets  = 1000
+	histogramBucketLabel = time.RFC3339
+)
+
+// NewFacets converts the requested facets into a bleve facets request.
+func NewFacets(facets map[string]models.FacetRequest) (bleve.FacetsRequest, error) {
+	if len(facets) == 0 {
+		return nil, nil
+	}
+	req := make(bleve.FacetsRequest, len(facets))
+	for name, facet := range facets {
+		size := facet.Size
+		if size == 0 {
+			size = defaultFacetSize
+		}
+		fr := bleve.NewFacetRequest(facet.Field, size)
+		for _, r := range facet.NumericRanges {
+			fr.AddNumericRange(r.Name, r.Min, r.Max)
+		}
+		for _, r := range facet.DateRanges {
+			start, err := ParseDate(r.Start)
+			if err != nil {
+				return nil, err
+			}
+			end, err := ParseDate(r.End)
+			if err != nil {
+				return nil, err
+			}
+			fr.AddDateTimeRange(r.Name, start, end)
+		}
+		if facet.DateHistogram != nil {
+			if err := addHistogram(fr, facet.DateHistogram); err != nil {
+				return nil, fmt.Errorf("facet %s: %v", name, err)
+			}
+		}
+		req[name] = fr
+	}
+	return req, nil
+}
+
+// addHistogram splits the histogram window into one date range bucket per interval.
+func addHistogram(fr *bleve.FacetRequest, histogram *models.DateHistogram) error {
+	buckets, err := histogramBuckets(histogram)
+	if err != nil {
+		return err
+	}
+	for _, bucket := range buckets {
+		fr.AddDateTimeRange(bucket.name, bucket.start, bucket.end)
+	}
+	if fr.Size < len(buckets) {
+		fr.Size = len(buckets)
+	}
+	return nil
+}
+
+// FillHistograms lists every bucket of the date histograms in time order, bleve leaves out empty ones.
+func FillHistograms(facets map[string]models.FacetRequest, results search.FacetResults) {
+	for name, facet := range facets {
+		result, ok := results[name]
+		if facet.DateHistogram == nil || !ok {
+			continue
+		}
+		buckets, err := histogramBuckets(facet.DateHistogram)
+		if err != nil {
+			continue
+		}
+		counts := make(map[string]int, len(result.DateRanges))
+		for _, r := range result.DateRanges {
+			counts[r.Name] = r.Count
+		}
+		ranges := make(search.DateRangeFacets, 0, len(buckets))
+		for _, bucket := range buckets {
+			start, end := bucket.start.Format(time.RFC3339Nano), bucket.end.Format(time.RFC3339Nano)
+			ranges = append(ranges, &search.DateRangeFacet{Name: bucket.name, Start: &start, End: &end, Count: counts[bucket.name]})
+		}
+		result.DateRanges = ranges
+	}
+}
+
+type histogramBucket struct {
+	name       string
+	start, end time.Time
+}
+
+func histogramBuckets(histogram *models.DateHistogram) ([]histogramBucket, error) {
+	start, err := ParseDate(histogram.Start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := ParseDate(histogram.End)
+	if err != nil {
+		return nil, err
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("date histogram end must be after start")
+	}
+	buckets := make([]histogramBucket, 0)
+	for from := start; from.Before(end); {
+		if len(buckets) == maxHistogramBuckets {
+			return nil, fmt.Errorf("date histogram exceeds %d buckets", maxHistogramBuckets)
+		}
+		to := nextInterval(from, histogram.Interval)
+		if to.After(end) {
+			to = end
+		}
+		buckets = append(buckets, histogramBucket{name: from.Format(histogramBucketLabel), start: from, end: to})
+		from = to
+	}
+	return buckets, nil
+}
+
+func nextInterval(t time.Time, interval string) time.Time {
+	switch interval {
+	case "hour":
+		return t.Add(time.Hour)
+	case "week":
+		return t.AddDate(0, 0, 7)
+	case "month":
+		return t.AddDate(0, 1, 0)
+	case "year":
+		return t.AddDate(1, 0, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
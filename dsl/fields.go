@@ -0,0 +1,91 @@
+package dsl
+
+import (
+	scoutmap "Scout.go/mapping"
+	"Scout.go/models"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// SeparateFields lists the string fields left out of the composite _all field for their own analyzer.
+func SeparateFields(config *models.IndexMapConfig) []string {
+	fields := make([]string, 0)
+	if config == nil {
+		return fields
+	}
+	for _, searchable := range config.Searchable {
+		if searchable.IsText() && !scoutmap.IncludedInAll(searchable, config) {
+			fields = append(fields, searchable.Field)
+		}
+	}
+	return fields
+}
+
+// SpreadOverFields also runs every clause that searches the composite field on each of the given fields, which
+// analyze the text with their own analyzer. Query strings are parsed first so their clauses are spread as well.
+func SpreadOverFields(q query.Query, fields []string) query.Query {
+	if len(fields) == 0 {
+		return q
+	}
+	switch q := q.(type) {
+	case *query.QueryStringQuery:
+		parsed, err := q.Parse()
+		if err != nil {
+			return q
+		}
+		return SpreadOverFields(parsed, fields)
+	case *query.BooleanQuery:
+		if q.Must != nil {
+			q.Must = SpreadOverFields(q.Must, fields)
+		}
+		if q.Should != nil {
+			q.Should = SpreadOverFields(q.Should, fields)
+		}
+		if q.MustNot != nil {
+			q.MustNot = SpreadOverFields(q.MustNot, fields)
+		}
+		return q
+	case *query.ConjunctionQuery:
+		for i := range q.Conjuncts {
+			q.Conjuncts[i] = SpreadOverFields(q.Conjuncts[i], fields)
+		}
+		return q
+	case *query.DisjunctionQuery:
+		for i := range q.Disjuncts {
+			q.Disjuncts[i] = SpreadOverFields(q.Disjuncts[i], fields)
+		}
+		return q
+	case *query.MatchQuery:
+		return spread(q, fields)
+	case *query.MatchPhraseQuery:
+		return spread(q, fields)
+	case *query.TermQuery:
+		return spread(q, fields)
+	case *query.FuzzyQuery:
+		return spread(q, fields)
+	case *query.PrefixQuery:
+		return spread(q, fields)
+	case *query.WildcardQuery:
+		return spread(q, fields)
+	default:
+		return q
+	}
+}
+
+// spread turns a clause without a field into a disjunction of itself and a copy per field.
+func spread[T any, Q interface {
+	*T
+	query.FieldableQuery
+}](q Q, fields []string) query.Query {
+	if q.Field() != "" {
+		return q
+	}
+	variants := []query.Query{q}
+	for _, field := range fields {
+		v := Q(new(T))
+		*v = *q
+		v.SetField(field)
+		variants = append(variants, v)
+	}
+	return bleve.NewDisjunctionQuery(variants...)
+}
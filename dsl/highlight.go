@@ -0,0 +1,78 @@
+package dsl
+
+import (
+	"Scout.go/models"
+	"github.com/blevesearch/bleve/v2/document"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/highlight"
+	"github.com/blevesearch/bleve/v2/search/highlight/format/ansi"
+	"github.com/blevesearch/bleve/v2/search/highlight/format/html"
+	simplefragmenter "github.com/blevesearch/bleve/v2/search/highlight/fragmenter/simple"
+	"github.com/blevesearch/bleve/v2/search/highlight/highlighter/simple"
+)
+
+const (
+	defaultPreTag        = "<mark>"
+	defaultPostTag       = "</mark>"
+	defaultFragments     = 1
+	defaultFragmentSize  = 200
+	highlightedSeparator = "…"
+)
+
+// NewHighlighter builds a highlighter honouring the requested style and tags.
+func NewHighlighter(h *models.HighlightRequest) *simple.Highlighter {
+	var formatter highlight.FragmentFormatter
+	if h.Style == "ansi" {
+		color := h.PreTag
+		if color == "" {
+			color = ansi.DefaultAnsiHighlight
+		}
+		formatter = ansi.NewFragmentFormatter(color)
+	} else {
+		pre, post := h.PreTag, h.PostTag
+		if pre == "" {
+			pre = defaultPreTag
+		}
+		if post == "" {
+			post = defaultPostTag
+		}
+		formatter = html.NewFragmentFormatter(pre, post)
+	}
+	size := h.FragmentSize
+	if size == 0 {
+		size = defaultFragmentSize
+	}
+	return simple.NewHighlighter(simplefragmenter.NewFragmenter(size), formatter, highlightedSeparator)
+}
+
+// Highlight returns the best fragments per field, the hit must carry term locations and the stored field values.
+func Highlight(hit *search.DocumentMatch, highlighter *simple.Highlighter, fields []string, fragments int) map[string][]string {
+	if fragments == 0 {
+		fragments = defaultFragments
+	}
+	doc := document.NewDocument(hit.ID)
+	for _, field := range fields {
+		switch v := hit.Fields[field].(type) {
+		case string:
+			doc.AddField(document.NewTextField(field, nil, []byte(v)))
+		case []interface{}:
+			for pos, item := range v {
+				if s, ok := item.(string); ok {
+					doc.AddField(document.NewTextField(field, []uint64{uint64(pos)}, []byte(s)))
+				}
+			}
+		}
+	}
+
+	res := make(map[string][]string)
+	for _, field := range fields {
+		if _, ok := hit.Locations[field]; !ok {
+			continue
+		}
+		best := highlighter.BestFragmentsInField(hit, doc, field, fragments)
+		if len(best) > 0 {
+			res[field] = best
+		}
+	}
+	return res
+}
@@ -0,0 +1,83 @@
+package dsl
+
+import (
+	"Scout.go/models"
+	"fmt"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"strconv"
+	"strings"
+)
+
+const defaultFieldBoost = 1.0
+
+// NewMultiMatchQuery matches the text on every field and weighs each match by the field boost.
+// Without fields the text is spread across all string fields of the index with their configured boost.
+func NewMultiMatchQuery(clause *models.MultiMatchClause, config *models.IndexMapConfig) (query.Query, error) {
+	fields := clause.Fields
+	if len(fields) == 0 {
+		fields = stringFields(config)
+	}
+	and := clause.Operator == "and"
+	matches := make([]query.Query, 0, len(fields))
+	for _, f := range fields {
+		field, boost, err := parseWeightedField(f, config)
+		if err != nil {
+			return nil, err
+		}
+		var q query.Query
+		if clause.Fuzziness == 0 && TypoEnabled(config) {
+			q = NewTypoQuery(clause.Query, field, and, config)
+		} else {
+			mq := bleve.NewMatchQuery(clause.Query)
+			mq.SetFuzziness(clause.Fuzziness)
+			if and {
+				mq.SetOperator(query.MatchQueryOperatorAnd)
+			}
+			q = mq
+		}
+		matches = append(matches, withFieldAndBoost(q, field, &boost))
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("multi_match requires at least one string field")
+	}
+	return withFieldAndBoost(bleve.NewDisjunctionQuery(matches...), "", clause.Boost), nil
+}
+
+// HasFieldBoosts reports whether any field of the index is weighted differently from the rest.
+func HasFieldBoosts(config *models.IndexMapConfig) bool {
+	for _, searchable := range config.Searchable {
+		if searchable.Boost != 0 && searchable.Boost != defaultFieldBoost {
+			return true
+		}
+	}
+	return false
+}
+
+// parseWeightedField splits "field^weight", a field without weight takes its configured boost.
+func parseWeightedField(f string, config *models.IndexMapConfig) (string, float64, error) {
+	field, weight, weighted := strings.Cut(f, "^")
+	if weighted {
+		boost, err := strconv.ParseFloat(weight, 64)
+		if err != nil || boost < 0 {
+			return "", 0, fmt.Errorf("invalid weight for field %s", field)
+		}
+		return field, boost, nil
+	}
+	for _, searchable := range config.Searchable {
+		if searchable.Field == field && searchable.Boost != 0 {
+			return field, searchable.Boost, nil
+		}
+	}
+	return field, defaultFieldBoost, nil
+}
+
+func stringFields(config *models.IndexMapConfig) []string {
+	fields := make([]string, 0)
+	for _, searchable := range config.Searchable {
+		if searchable.IsText() {
+			fields = append(fields, searchable.Field)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,205 @@
+package dsl
+
+import (
+	"Scout.go/models"
+	"fmt"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/geo"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"time"
+)
+
+var dateLayouts = []string{time.RFC3339Nano, time.DateTime, time.DateOnly}
+
+// NewQuery converts a search DSL clause into the matching bleve query, nil clause matches all documents.
+// Match clauses without explicit fuzziness follow the typo tolerance of the index.
+func NewQuery(clause *models.QueryClause, config *models.IndexMapConfig) (query.Query, error) {
+	if clause == nil || clause.MatchAll != nil {
+		return bleve.NewMatchAllQuery(), nil
+	}
+	if clause.QueryString != "" {
+		return bleve.NewQueryStringQuery(clause.QueryString), nil
+	}
+	if clause.Bool != nil {
+		return newBoolQuery(clause.Bool, config)
+	}
+	if clause.Term != nil {
+		q := bleve.NewTermQuery(clause.Term.Value)
+		return withFieldAndBoost(q, clause.Term.Field, clause.Term.Boost), nil
+	}
+	if clause.Match != nil {
+		if clause.Match.Fuzziness == 0 && TypoEnabled(config) {
+			q := NewTypoQuery(clause.Match.Query, clause.Match.Field, clause.Match.Operator == "and", config)
+			return withFieldAndBoost(q, "", clause.Match.Boost), nil
+		}
+		q := bleve.NewMatchQuery(clause.Match.Query)
+		q.SetFuzziness(clause.Match.Fuzziness)
+		if clause.Match.Operator == "and" {
+			q.SetOperator(query.MatchQueryOperatorAnd)
+		}
+		return withFieldAndBoost(q, clause.Match.Field, clause.Match.Boost), nil
+	}
+	if clause.MultiMatch != nil {
+		return NewMultiMatchQuery(clause.MultiMatch, config)
+	}
+	if clause.Phrase != nil {
+		q := bleve.NewMatchPhraseQuery(clause.Phrase.Query)
+		q.SetFuzziness(clause.Phrase.Fuzziness)
+		return withFieldAndBoost(q, clause.Phrase.Field, clause.Phrase.Boost), nil
+	}
+	if clause.Prefix != nil {
+		q := bleve.NewPrefixQuery(clause.Prefix.Value)
+		return withFieldAndBoost(q, clause.Prefix.Field, clause.Prefix.Boost), nil
+	}
+	if clause.Wildcard != nil {
+		q := bleve.NewWildcardQuery(clause.Wildcard.Value)
+		return withFieldAndBoost(q, clause.Wildcard.Field, clause.Wildcard.Boost), nil
+	}
+	if clause.Regexp != nil {
+		q := bleve.NewRegexpQuery(clause.Regexp.Value)
+		return withFieldAndBoost(q, clause.Regexp.Field, clause.Regexp.Boost), nil
+	}
+	if clause.Range != nil {
+		return newRangeQuery(clause.Range), nil
+	}
+	if clause.DateRange != nil {
+		return newDateRangeQuery(clause.DateRange)
+	}
+	if clause.IPRange != nil {
+		q := bleve.NewIPRangeQuery(clause.IPRange.CIDR)
+		return withFieldAndBoost(q, clause.IPRange.Field, clause.IPRange.Boost), nil
+	}
+	if clause.GeoDistance != nil {
+		g := clause.GeoDistance
+		q := bleve.NewGeoDistanceQuery(g.Origin.Lon, g.Origin.Lat, g.Distance)
+		return withFieldAndBoost(q, g.Field, g.Boost), nil
+	}
+	if clause.GeoBBox != nil {
+		g := clause.GeoBBox
+		q := bleve.NewGeoBoundingBoxQuery(g.TopLeft.Lon, g.TopLeft.Lat, g.BottomRight.Lon, g.BottomRight.Lat)
+		return withFieldAndBoost(q, g.Field, g.Boost), nil
+	}
+	if clause.GeoPolygon != nil {
+		points := make([]geo.Point, 0, len(clause.GeoPolygon.Points))
+		for _, p := range clause.GeoPolygon.Points {
+			points = append(points, geo.Point{Lat: p.Lat, Lon: p.Lon})
+		}
+		q := query.NewGeoBoundingPolygonQuery(points)
+		return withFieldAndBoost(q, clause.GeoPolygon.Field, clause.GeoPolygon.Boost), nil
+	}
+	return nil, fmt.Errorf("unsupported query clause")
+}
+
+func newBoolQuery(clause *models.BoolClause, config *models.IndexMapConfig) (query.Query, error) {
+	must, err := newQueries(clause.Must, config)
+	if err != nil {
+		return nil, err
+	}
+	should, err := newQueries(clause.Should, config)
+	if err != nil {
+		return nil, err
+	}
+	mustNot, err := newQueries(clause.MustNot, config)
+	if err != nil {
+		return nil, err
+	}
+	q := bleve.NewBooleanQuery()
+	q.AddMust(must...)
+	q.AddShould(should...)
+	q.AddMustNot(mustNot...)
+	if clause.MinShould > 0 {
+		q.SetMinShould(float64(clause.MinShould))
+	}
+	return withFieldAndBoost(q, "", clause.Boost), nil
+}
+
+func newQueries(clauses []models.QueryClause, config *models.IndexMapConfig) ([]query.Query, error) {
+	queries := make([]query.Query, 0, len(clauses))
+	for i := range clauses {
+		q, err := NewQuery(&clauses[i], config)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+	return queries, nil
+}
+
+func newRangeQuery(clause *models.RangeClause) query.Query {
+	min, minInclusive := clause.Gte, true
+	if clause.Gt != nil {
+		min, minInclusive = clause.Gt, false
+	}
+	max, maxInclusive := clause.Lte, true
+	if clause.Lt != nil {
+		max, maxInclusive = clause.Lt, false
+	}
+	q := bleve.NewNumericRangeInclusiveQuery(min, max, &minInclusive, &maxInclusive)
+	return withFieldAndBoost(q, clause.Field, clause.Boost)
+}
+
+func newDateRangeQuery(clause *models.DateRangeClause) (query.Query, error) {
+	startStr, startInclusive := clause.Gte, true
+	if clause.Gt != "" {
+		startStr, startInclusive = clause.Gt, false
+	}
+	endStr, endInclusive := clause.Lte, true
+	if clause.Lt != "" {
+		endStr, endInclusive = clause.Lt, false
+	}
+	start, err := ParseDate(startStr)
+	if err != nil {
+		return nil, err
+	}
+	end, err := ParseDate(endStr)
+	if err != nil {
+		return nil, err
+	}
+	q := bleve.NewDateRangeInclusiveQuery(start, end, &startInclusive, &endInclusive)
+	return withFieldAndBoost(q, clause.Field, clause.Boost), nil
+}
+
+// ParseDate accepts RFC3339, "2006-01-02 15:04:05" or "2006-01-02", empty string gives the zero time.
+func ParseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %s", value)
+}
+
+func withFieldAndBoost(q query.Query, field string, boost *float64) query.Query {
+	if fq, ok := q.(query.FieldableQuery); ok && field != "" {
+		fq.SetField(field)
+	}
+	if boost != nil {
+		applyBoost(q, *boost)
+	}
+	return q
+}
+
+// applyBoost multiplies the boost into the leaf queries, bleve ignores the boost of compound queries.
+func applyBoost(q query.Query, boost float64) {
+	switch q := q.(type) {
+	case *query.BooleanQuery:
+		for _, child := range []query.Query{q.Must, q.Should, q.MustNot} {
+			if child != nil {
+				applyBoost(child, boost)
+			}
+		}
+	case *query.ConjunctionQuery:
+		for _, child := range q.Conjuncts {
+			applyBoost(child, boost)
+		}
+	case *query.DisjunctionQuery:
+		for _, child := range q.Disjuncts {
+			applyBoost(child, boost)
+		}
+	case query.BoostableQuery:
+		q.SetBoost(q.Boost() * boost)
+	}
+}
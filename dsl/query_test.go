@@ -0,0 +1,49 @@
+package dsl
+
+import (
+	"Scout.go/models"
+	"encoding/json"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"testing"
+	"time"
+)
+
+func TestNewQueryBoostReachesLeaves(t *testing.T) {
+	clause := &models.QueryClause{}
+	if err := json.Unmarshal([]byte(`{"bool":{"should":[{"term":{"field":"sku","value":"p1","boost":2}}],"boost":3}}`), clause); err != nil {
+		t.Fatal(err)
+	}
+	q, err := NewQuery(clause, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	should := q.(*query.BooleanQuery).Should.(*query.DisjunctionQuery)
+	if got := should.Disjuncts[0].(*query.TermQuery).Boost(); got != 6 {
+		t.Errorf("leaf boost = %v, want 6", got)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), false},
+		{"2024-01-02 03:04:05", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"2024-01-02T03:04:05Z", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"02/01/2024", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDate(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
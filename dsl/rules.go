@@ -0,0 +1,189 @@
+package dsl
+
+import (
+	"Scout.go/models"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Merchandising is the merged effect of the rules that match a query.
+type Merchandising struct {
+	Pins   []models.PinnedDoc
+	Hidden map[string]bool
+	Boosts map[string]float64
+}
+
+// NewMerchandising merges the rules active at now whose pattern matches the text, nil when none applies.
+func NewMerchandising(rules []models.MerchandisingRule, text string, now time.Time) *Merchandising {
+	text = normalizeQueryText(text)
+	if text == "" {
+		return nil
+	}
+	m := &Merchandising{Hidden: make(map[string]bool), Boosts: make(map[string]float64)}
+	matched := false
+	for _, rule := range rules {
+		if !ruleMatches(rule, text) || !ruleActive(rule, now) {
+			continue
+		}
+		matched = true
+		m.Pins = append(m.Pins, rule.Pins...)
+		for _, id := range rule.Hide {
+			m.Hidden[id] = true
+		}
+		for _, boost := range rule.Boosts {
+			if current, ok := m.Boosts[boost.Id]; ok {
+				boost.Boost *= current
+			}
+			m.Boosts[boost.Id] = boost.Boost
+		}
+	}
+	if !matched {
+		return nil
+	}
+	// hiding wins over pinning, the first rule pinning a document decides its position
+	pins := make([]models.PinnedDoc, 0, len(m.Pins))
+	seen := make(map[string]bool)
+	for _, pin := range m.Pins {
+		if !m.Hidden[pin.Id] && !seen[pin.Id] {
+			pins = append(pins, pin)
+			seen[pin.Id] = true
+		}
+	}
+	sort.SliceStable(pins, func(a, b int) bool { return pins[a].Position < pins[b].Position })
+	m.Pins = pins
+	return m
+}
+
+// Places reports whether the merchandising pins documents, which reorders the ranked window.
+func (m *Merchandising) Places() bool {
+	return len(m.Pins) > 0
+}
+
+// Unpinned drops the pins for result orders they can not be placed in, boosts are part of the query and stay.
+func (m *Merchandising) Unpinned() *Merchandising {
+	return &Merchandising{Hidden: m.Hidden, Boosts: m.Boosts}
+}
+
+// PinnedIds lists the pinned documents in position order.
+func (m *Merchandising) PinnedIds() []string {
+	ids := make([]string, 0, len(m.Pins))
+	for _, pin := range m.Pins {
+		ids = append(ids, pin.Id)
+	}
+	return ids
+}
+
+// Exclude keeps hidden and pinned documents out of the organic results, pinned ones are placed by Pin.
+func (m *Merchandising) Exclude(q query.Query) query.Query {
+	ids := m.PinnedIds()
+	for id := range m.Hidden {
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return q
+	}
+	bq := bleve.NewBooleanQuery()
+	bq.AddMust(q)
+	bq.AddMustNot(bleve.NewDocIDQuery(ids))
+	return bq
+}
+
+// Boost raises the score of the boosted documents inside the query, so a boosted document ranks up from
+// anywhere in the results. Each boost value is an optional clause of its own level, a document matches at
+// most one of them and no coordination factor waters the boost down.
+func (m *Merchandising) Boost(q query.Query) query.Query {
+	groups := make(map[float64][]string)
+	for id, boost := range m.Boosts {
+		groups[boost] = append(groups[boost], id)
+	}
+	boosts := make([]float64, 0, len(groups))
+	for boost := range groups {
+		boosts = append(boosts, boost)
+	}
+	sort.Float64s(boosts)
+	for _, boost := range boosts {
+		ids := groups[boost]
+		sort.Strings(ids)
+		boosted := bleve.NewDocIDQuery(ids)
+		boosted.SetBoost(boost)
+		bq := bleve.NewBooleanQuery()
+		bq.AddMust(q)
+		bq.AddShould(boosted)
+		q = bq
+	}
+	return q
+}
+
+// Pin inserts the pinned hits at their positions, positions past the end are appended in order. An organic
+// hit of a pinned document is dropped, it reports how many pinned documents were not among the hits.
+func (m *Merchandising) Pin(hits search.DocumentMatchCollection, pinned map[string]*search.DocumentMatch) (search.DocumentMatchCollection, int) {
+	res := make(search.DocumentMatchCollection, 0, len(hits)+len(pinned))
+	for _, hit := range hits {
+		if _, ok := pinned[hit.ID]; !ok {
+			res = append(res, hit)
+		}
+	}
+	added := len(pinned) - (len(hits) - len(res))
+	for _, pin := range m.Pins {
+		hit, ok := pinned[pin.Id]
+		if !ok {
+			continue
+		}
+		at := pin.Position - 1
+		if at > len(res) {
+			at = len(res)
+		}
+		res = append(res, nil)
+		copy(res[at+1:], res[at:])
+		res[at] = hit
+	}
+	return res, added
+}
+
+// QueryText extracts the user typed text a rule pattern is matched against.
+func QueryText(clause *models.QueryClause) string {
+	switch {
+	case clause == nil:
+		return ""
+	case clause.QueryString != "":
+		return clause.QueryString
+	case clause.Match != nil:
+		return clause.Match.Query
+	case clause.MultiMatch != nil:
+		return clause.MultiMatch.Query
+	case clause.Phrase != nil:
+		return clause.Phrase.Query
+	default:
+		return ""
+	}
+}
+
+func ruleMatches(rule models.MerchandisingRule, text string) bool {
+	pattern := normalizeQueryText(rule.Pattern)
+	switch rule.Match {
+	case "prefix":
+		return strings.HasPrefix(text, pattern)
+	case "contains":
+		return strings.Contains(text, pattern)
+	default:
+		return text == pattern
+	}
+}
+
+func ruleActive(rule models.MerchandisingRule, now time.Time) bool {
+	if start, err := ParseDate(rule.Start); err != nil || (rule.Start != "" && now.Before(start)) {
+		return false
+	}
+	if end, err := ParseDate(rule.End); err != nil || (rule.End != "" && !now.Before(end)) {
+		return false
+	}
+	return true
+}
+
+func normalizeQueryText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
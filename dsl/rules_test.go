@@ -0,0 +1,107 @@
+package dsl
+
+import (
+	"Scout.go/models"
+	"github.com/blevesearch/bleve/v2/search"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewMerchandising(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	rules := []models.MerchandisingRule{
+		{Id: "exact", Pattern: "Running Shoes", Pins: []models.PinnedDoc{{Id: "a", Position: 2}, {Id: "b", Position: 1}}},
+		{Id: "prefix", Pattern: "running", Match: "prefix", Hide: []string{"a"}, Boosts: []models.BoostedDoc{{Id: "c", Boost: 2}}},
+		{Id: "contains", Pattern: "shoe", Match: "contains", Boosts: []models.BoostedDoc{{Id: "c", Boost: 3}}},
+		{Id: "expired", Pattern: "sandals", Hide: []string{"d"}, End: "2024-01-01"},
+		{Id: "upcoming", Pattern: "boots", Hide: []string{"e"}, Start: "2024-07-01"},
+	}
+	tests := []struct {
+		name   string
+		text   string
+		pins   []string
+		hidden []string
+		boosts map[string]float64
+	}{
+		{"exact with spacing and case", "  running   SHOES", []string{"b"}, []string{"a"}, map[string]float64{"c": 6}},
+		{"prefix only", "running socks", []string{}, []string{"a"}, map[string]float64{"c": 2}},
+		{"contains only", "red shoe", []string{}, []string{}, map[string]float64{"c": 3}},
+		{"expired", "sandals", nil, nil, nil},
+		{"not started", "boots", nil, nil, nil},
+		{"empty text", "", nil, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMerchandising(rules, tt.text, now)
+			if tt.pins == nil {
+				if m != nil {
+					t.Fatalf("NewMerchandising() = %+v, want nil", m)
+				}
+				return
+			}
+			if m == nil {
+				t.Fatal("NewMerchandising() = nil")
+			}
+			hidden := []string{}
+			for id := range m.Hidden {
+				hidden = append(hidden, id)
+			}
+			if !reflect.DeepEqual(m.PinnedIds(), tt.pins) || !reflect.DeepEqual(hidden, tt.hidden) || !reflect.DeepEqual(m.Boosts, tt.boosts) {
+				t.Errorf("NewMerchandising() = pins %v hidden %v boosts %v, want %v %v %v", m.PinnedIds(), hidden, m.Boosts, tt.pins, tt.hidden, tt.boosts)
+			}
+		})
+	}
+}
+
+func TestMerchandisingPin(t *testing.T) {
+	m := &Merchandising{Pins: []models.PinnedDoc{{Id: "p1", Position: 1}, {Id: "p2", Position: 3}, {Id: "p3", Position: 10}, {Id: "gone", Position: 2}}}
+	pinned := map[string]*search.DocumentMatch{"p1": {ID: "p1"}, "p2": {ID: "p2"}, "p3": {ID: "p3"}}
+	tests := []struct {
+		name      string
+		hits      []string
+		want      []string
+		wantAdded int
+	}{
+		{"organic hits", []string{"a", "b", "c"}, []string{"p1", "a", "p2", "b", "c", "p3"}, 3},
+		{"no hits", []string{}, []string{"p1", "p2", "p3"}, 3},
+		{"pinned among hits", []string{"a", "p2", "b"}, []string{"p1", "a", "p2", "b", "p3"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := make(search.DocumentMatchCollection, 0, len(tt.hits))
+			for _, id := range tt.hits {
+				hits = append(hits, &search.DocumentMatch{ID: id})
+			}
+			res, added := m.Pin(hits, pinned)
+			got := make([]string, 0, len(res))
+			for _, hit := range res {
+				got = append(got, hit.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) || added != tt.wantAdded {
+				t.Errorf("Pin() = %v, %d, want %v, %d", got, added, tt.want, tt.wantAdded)
+			}
+		})
+	}
+}
+
+func TestQueryText(t *testing.T) {
+	tests := []struct {
+		name   string
+		clause *models.QueryClause
+		want   string
+	}{
+		{"nil", nil, ""},
+		{"query string", &models.QueryClause{QueryString: "shoes"}, "shoes"},
+		{"match", &models.QueryClause{Match: &models.MatchClause{Query: "red shoes"}}, "red shoes"},
+		{"multi match", &models.QueryClause{MultiMatch: &models.MultiMatchClause{Query: "boots"}}, "boots"},
+		{"term", &models.QueryClause{Term: &models.TermClause{Value: "p1"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QueryText(tt.clause); got != tt.want {
+				t.Errorf("QueryText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package dsl
+
+import (
+	"Scout.go/models"
+	"fmt"
+	"github.com/blevesearch/bleve/v2/search"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	defaultRescoreWindow = 100
+	defaultDecay         = 0.5
+	defaultWeight        = 1.0
+)
+
+// Rescoring reports whether the scoring has any function to apply.
+func Rescoring(scoring *models.Scoring) bool {
+	return scoring != nil && len(scoring.Functions) > 0
+}
+
+// RescoreWindow is the number of top hits re-ranked. It does not depend on the requested page, so every
+// page is cut from the same ranking and hits past the window keep their text score order.
+func RescoreWindow(scoring *models.Scoring) int {
+	if scoring.Window == 0 {
+		return defaultRescoreWindow
+	}
+	return scoring.Window
+}
+
+// Rescore combines the text score of every hit with the scoring functions and re-ranks the hits.
+// Multiply mode scales the score by 1 + the weighted function values, sum mode adds them to it.
+func Rescore(hits search.DocumentMatchCollection, scoring *models.Scoring, config *models.IndexMapConfig) error {
+	now := time.Now()
+	for _, fn := range scoring.Functions {
+		if err := checkScoringField(fn, config); err != nil {
+			return err
+		}
+	}
+	for _, hit := range hits {
+		combined := 0.0
+		for _, fn := range scoring.Functions {
+			value, err := scoringValue(fn, hit.Fields[fn.Field], now)
+			if err != nil {
+				return err
+			}
+			weight := fn.Weight
+			if weight == 0 {
+				weight = defaultWeight
+			}
+			combined += weight * value
+		}
+		if scoring.Mode == "sum" {
+			hit.Score += combined
+		} else {
+			hit.Score *= 1 + combined
+		}
+	}
+	sort.SliceStable(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].ID < hits[b].ID
+	})
+	return nil
+}
+
+// scoringValue evaluates one function for a stored field value, a missing value contributes nothing.
+func scoringValue(fn models.ScoringFunction, stored interface{}, now time.Time) (float64, error) {
+	if values, ok := stored.([]interface{}); ok {
+		if len(values) == 0 {
+			return 0, nil
+		}
+		stored = values[0]
+	}
+	switch fn.Type {
+	case "decay":
+		s, ok := stored.(string)
+		if !ok {
+			return 0, nil
+		}
+		t, err := ParseDate(s)
+		if err != nil {
+			return 0, nil
+		}
+		origin := now
+		if fn.Origin != "" {
+			if origin, err = ParseDate(fn.Origin); err != nil {
+				return 0, err
+			}
+		}
+		scale, _ := time.ParseDuration(fn.Scale)
+		decay := fn.Decay
+		if decay == 0 {
+			decay = defaultDecay
+		}
+		age := math.Abs(origin.Sub(t).Hours()) / scale.Hours()
+		return math.Pow(decay, age), nil
+	case "log":
+		v, ok := stored.(float64)
+		if !ok || v <= 0 {
+			return 0, nil
+		}
+		return math.Log1p(v), nil
+	default:
+		v, _ := stored.(float64)
+		return v, nil
+	}
+}
+
+func checkScoringField(fn models.ScoringFunction, config *models.IndexMapConfig) error {
+	want := models.Number
+	if fn.Type == "decay" {
+		want = models.DateTime
+	}
+	for _, searchable := range config.Searchable {
+		if searchable.Field == fn.Field && searchable.Type == want {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s scoring function requires %s field %s", fn.Type, want, fn.Field)
+}
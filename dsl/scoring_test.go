@@ -0,0 +1,98 @@
+package dsl
+
+import (
+	"Scout.go/models"
+	"github.com/blevesearch/bleve/v2/search"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRescore(t *testing.T) {
+	config := &models.IndexMapConfig{UniqueId: "id", Searchable: []models.IndexSearchable{
+		{Field: "sales", Type: models.Number}, {Field: "created", Type: models.DateTime}, {Field: "title", Type: models.String}}}
+	hits := func() search.DocumentMatchCollection {
+		return search.DocumentMatchCollection{
+			{ID: "a", Score: 2, Fields: map[string]interface{}{"sales": 0.0, "created": "2024-01-01T00:00:00Z"}},
+			{ID: "b", Score: 1, Fields: map[string]interface{}{"sales": 2.0, "created": "2023-12-01T00:00:00Z"}},
+			{ID: "c", Score: 1, Fields: map[string]interface{}{}},
+		}
+	}
+	tests := []struct {
+		name    string
+		scoring models.Scoring
+		want    []string
+		wantErr bool
+	}{
+		{"static multiply", models.Scoring{Functions: []models.ScoringFunction{{Type: "static", Field: "sales"}}}, []string{"b", "a", "c"}, false},
+		{"log sum", models.Scoring{Mode: "sum", Functions: []models.ScoringFunction{{Type: "log", Field: "sales", Weight: 0.5}}}, []string{"a", "b", "c"}, false},
+		{"decay", models.Scoring{Functions: []models.ScoringFunction{{Type: "decay", Field: "created", Scale: "744h", Origin: "2024-01-01T00:00:00Z"}}}, []string{"a", "b", "c"}, false},
+		{"wrong field type", models.Scoring{Functions: []models.ScoringFunction{{Type: "static", Field: "title"}}}, nil, true},
+		{"unknown field", models.Scoring{Functions: []models.ScoringFunction{{Type: "decay", Field: "sales", Scale: "1h"}}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked := hits()
+			err := Rescore(ranked, &tt.scoring, config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Rescore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := make([]string, 0, len(ranked))
+			for _, hit := range ranked {
+				got = append(got, hit.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Rescore() order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoringValue(t *testing.T) {
+	now := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		fn     models.ScoringFunction
+		stored interface{}
+		want   float64
+	}{
+		{"static", models.ScoringFunction{Type: "static"}, 4.0, 4},
+		{"static array takes the first value", models.ScoringFunction{Type: "static"}, []interface{}{3.0, 9.0}, 3},
+		{"empty array", models.ScoringFunction{Type: "static"}, []interface{}{}, 0},
+		{"log", models.ScoringFunction{Type: "log"}, math.E - 1, 1},
+		{"log of a negative value", models.ScoringFunction{Type: "log"}, -5.0, 0},
+		{"decay after one scale", models.ScoringFunction{Type: "decay", Scale: "720h"}, "2024-01-01T00:00:00Z", 0.5},
+		{"decay with custom rate", models.ScoringFunction{Type: "decay", Scale: "720h", Decay: 0.25}, "2024-01-01T00:00:00Z", 0.25},
+		{"decay of a missing date", models.ScoringFunction{Type: "decay", Scale: "720h"}, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scoringValue(tt.fn, tt.stored, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("scoringValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRescoreWindow(t *testing.T) {
+	tests := []struct {
+		window int
+		want   int
+	}{
+		{0, defaultRescoreWindow},
+		{25, 25},
+	}
+	for _, tt := range tests {
+		if got := RescoreWindow(&models.Scoring{Window: tt.window}); got != tt.want {
+			t.Errorf("RescoreWindow(%d) = %d, want %d", tt.window, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package dsl
+
+import (
+	scoutmap "Scout.go/mapping"
+	"Scout.go/models"
+	"fmt"
+	"github.com/blevesearch/bleve/v2/search"
+)
+
+const defaultGeoUnit = "m"
+
+// NewSort converts sort specs into a bleve sort order, string fields are sorted on their keyword sub-field.
+// Relevance is the default and _id is always the last key so ties, and therefore cursors, are stable.
+func NewSort(specs []models.SortSpec, config *models.IndexMapConfig) (search.SortOrder, error) {
+	if len(specs) == 0 {
+		specs = []models.SortSpec{{Field: "_score"}}
+	}
+	order := make(search.SortOrder, 0, len(specs)+1)
+	hasId := false
+	for _, spec := range specs {
+		desc := spec.Order == "desc"
+		switch spec.Field {
+		case "_score":
+			order = append(order, &search.SortScore{Desc: spec.Order != "asc"})
+			continue
+		case "_id":
+			order = append(order, &search.SortDocID{Desc: desc})
+			hasId = true
+			continue
+		}
+		if spec.Origin != nil {
+			gs, err := newGeoSort(spec, config)
+			if err != nil {
+				return nil, err
+			}
+			order = append(order, gs)
+			continue
+		}
+		field, typ, err := sortableField(spec.Field, config)
+		if err != nil {
+			return nil, err
+		}
+		sf := &search.SortField{Field: field, Desc: desc, Type: typ}
+		if spec.Missing == "first" {
+			sf.Missing = search.SortFieldMissingFirst
+		}
+		order = append(order, sf)
+	}
+	if !hasId {
+		order = append(order, &search.SortDocID{})
+	}
+	return order, nil
+}
+
+func sortableField(field string, config *models.IndexMapConfig) (string, search.SortFieldType, error) {
+	for _, searchable := range config.Searchable {
+		if searchable.Field != field {
+			continue
+		}
+		switch searchable.Type {
+		case models.String, models.TextArray:
+			return scoutmap.SortField(field), search.SortFieldAsString, nil
+		case models.Keyword:
+			return field, search.SortFieldAsString, nil
+		case models.Number, models.NumberArray:
+			return field, search.SortFieldAsNumber, nil
+		case models.DateTime:
+			return field, search.SortFieldAsDate, nil
+		case models.GeoPoint:
+			return "", search.SortFieldAuto, fmt.Errorf("geopoint field %s is sorted by distance and requires origin", field)
+		default:
+			return field, search.SortFieldAuto, nil
+		}
+	}
+	return "", search.SortFieldAuto, fmt.Errorf("field %s is not sortable", field)
+}
+
+// newGeoSort orders by the distance between the geopoint field and the origin, nearest first unless desc.
+// Distances are in meters unless the spec names another unit.
+func newGeoSort(spec models.SortSpec, config *models.IndexMapConfig) (search.SearchSort, error) {
+	unit := spec.Unit
+	if unit == "" {
+		unit = defaultGeoUnit
+	}
+	for _, searchable := range config.Searchable {
+		if searchable.Field == spec.Field && searchable.Type == models.GeoPoint {
+			return search.NewSortGeoDistance(spec.Field, unit, spec.Origin.Lon, spec.Origin.Lat, spec.Order == "desc")
+		}
+	}
+	return nil, fmt.Errorf("field %s is not a geopoint", spec.Field)
+}
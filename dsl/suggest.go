@@ -0,0 +1,47 @@
+package dsl
+
+import (
+	scoutmap "Scout.go/mapping"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"strings"
+	"unicode"
+)
+
+// NewSuggestQuery matches every typed word as a prefix against the autocomplete sub-field of the given fields.
+func NewSuggestQuery(text string, fields []string) query.Query {
+	disjuncts := make([]query.Query, 0, len(fields))
+	for _, field := range fields {
+		q := bleve.NewMatchQuery(text)
+		q.SetField(scoutmap.AutocompleteField(field))
+		q.Analyzer = scoutmap.AutocompleteSearchAnalyzer
+		q.SetOperator(query.MatchQueryOperatorAnd)
+		disjuncts = append(disjuncts, q)
+	}
+	return bleve.NewDisjunctionQuery(disjuncts...)
+}
+
+// SuggestMatches reports whether every typed word prefixes a word of the value, so a hit only
+// suggests the fields that actually matched.
+func SuggestMatches(text, value string) bool {
+	words := splitWords(value)
+	for _, typed := range splitWords(text) {
+		matched := false
+		for _, word := range words {
+			if strings.HasPrefix(word, typed) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
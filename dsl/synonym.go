@@ -0,0 +1,120 @@
+package dsl
+
+import (
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"strings"
+)
+
+// Synonyms maps a lower cased term to the other members of its synonym groups.
+type Synonyms map[string][]string
+
+func NewSynonyms(groups [][]string) Synonyms {
+	synonyms := make(Synonyms)
+	for _, group := range groups {
+		for _, term := range group {
+			key := strings.ToLower(strings.TrimSpace(term))
+			for _, other := range group {
+				other = strings.TrimSpace(other)
+				if strings.ToLower(other) != key {
+					synonyms[key] = append(synonyms[key], other)
+				}
+			}
+		}
+	}
+	return synonyms
+}
+
+// ExpandSynonyms rewrites term, match and phrase queries into a disjunction of the original and its synonym variants.
+// Query strings are parsed first so their clauses are expanded as well.
+func ExpandSynonyms(q query.Query, synonyms Synonyms) query.Query {
+	if len(synonyms) == 0 {
+		return q
+	}
+	switch q := q.(type) {
+	case *query.QueryStringQuery:
+		parsed, err := q.Parse()
+		if err != nil {
+			return q
+		}
+		return ExpandSynonyms(parsed, synonyms)
+	case *query.BooleanQuery:
+		if q.Must != nil {
+			q.Must = ExpandSynonyms(q.Must, synonyms)
+		}
+		if q.Should != nil {
+			q.Should = ExpandSynonyms(q.Should, synonyms)
+		}
+		if q.MustNot != nil {
+			q.MustNot = ExpandSynonyms(q.MustNot, synonyms)
+		}
+		return q
+	case *query.ConjunctionQuery:
+		for i := range q.Conjuncts {
+			q.Conjuncts[i] = ExpandSynonyms(q.Conjuncts[i], synonyms)
+		}
+		return q
+	case *query.DisjunctionQuery:
+		for i := range q.Disjuncts {
+			q.Disjuncts[i] = ExpandSynonyms(q.Disjuncts[i], synonyms)
+		}
+		return q
+	case *query.TermQuery:
+		alternatives := synonyms[strings.ToLower(q.Term)]
+		if len(alternatives) == 0 {
+			return q
+		}
+		variants := []query.Query{q}
+		for _, alternative := range alternatives {
+			v := *q
+			v.Term = strings.ToLower(alternative)
+			variants = append(variants, &v)
+		}
+		return bleve.NewDisjunctionQuery(variants...)
+	case *query.MatchQuery:
+		texts := synonymVariants(q.Match, synonyms)
+		if len(texts) == 0 {
+			return q
+		}
+		variants := []query.Query{q}
+		for _, text := range texts {
+			v := *q
+			v.Match = text
+			variants = append(variants, &v)
+		}
+		return bleve.NewDisjunctionQuery(variants...)
+	case *query.MatchPhraseQuery:
+		texts := synonymVariants(q.MatchPhrase, synonyms)
+		if len(texts) == 0 {
+			return q
+		}
+		variants := []query.Query{q}
+		for _, text := range texts {
+			v := *q
+			v.MatchPhrase = text
+			variants = append(variants, &v)
+		}
+		return bleve.NewDisjunctionQuery(variants...)
+	default:
+		return q
+	}
+}
+
+// synonymVariants returns the text with the whole of it, or one word at a time, swapped for each synonym.
+func synonymVariants(text string, synonyms Synonyms) []string {
+	variants := make([]string, 0)
+	variants = append(variants, synonyms[strings.ToLower(strings.TrimSpace(text))]...)
+	words := strings.Fields(text)
+	if len(words) < 2 {
+		return variants
+	}
+	for i, word := range words {
+		for _, alternative := range synonyms[strings.ToLower(word)] {
+			replaced := make([]string, len(words))
+			copy(replaced, words)
+			replaced[i] = alternative
+			variants = append(variants, strings.Join(replaced, " "))
+		}
+	}
+	return variants
+}
@@ -0,0 +1,71 @@
+package dsl
+
+import (
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSynonymVariants(t *testing.T) {
+	synonyms := NewSynonyms([][]string{{"TV", "television"}, {"sofa", "couch"}})
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"tv", []string{"television"}},
+		{" TV ", []string{"television"}},
+		{"radio", []string{}},
+		{"tv sofa", []string{"television sofa", "tv couch"}},
+		{"television", []string{"TV"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := synonymVariants(tt.text, synonyms)
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("synonymVariants(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandSynonyms(t *testing.T) {
+	synonyms := NewSynonyms([][]string{{"tv", "television"}})
+	tests := []struct {
+		name     string
+		q        query.Query
+		variants int
+	}{
+		{"term", bleve.NewTermQuery("TV"), 2},
+		{"match", bleve.NewMatchQuery("tv"), 2},
+		{"phrase", bleve.NewMatchPhraseQuery("smart tv"), 2},
+		{"no synonym", bleve.NewMatchQuery("radio"), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expanded := ExpandSynonyms(tt.q, synonyms)
+			disjunction, ok := expanded.(*query.DisjunctionQuery)
+			switch {
+			case tt.variants == 0 && ok:
+				t.Errorf("ExpandSynonyms() = %T, want the query unchanged", expanded)
+			case tt.variants > 0 && (!ok || len(disjunction.Disjuncts) != tt.variants):
+				t.Errorf("ExpandSynonyms() = %#v, want %d variants", expanded, tt.variants)
+			}
+		})
+	}
+}
+
+func TestExpandSynonymsParsesQueryStrings(t *testing.T) {
+	synonyms := NewSynonyms([][]string{{"tv", "television"}})
+	expanded := ExpandSynonyms(bleve.NewQueryStringQuery("+tv"), synonyms)
+	bq, ok := expanded.(*query.BooleanQuery)
+	if !ok {
+		t.Fatalf("ExpandSynonyms() = %T, want the parsed boolean query", expanded)
+	}
+	must := bq.Must.(*query.ConjunctionQuery)
+	if _, ok := must.Conjuncts[0].(*query.DisjunctionQuery); !ok {
+		t.Errorf("must clause = %T, want the expanded disjunction", must.Conjuncts[0])
+	}
+}
@@ -0,0 +1,70 @@
+package dsl
+
+import (
+	"Scout.go/models"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"strings"
+)
+
+// exactMatchBoost keeps exact matches ranked above their fuzzy counterparts.
+const exactMatchBoost = 2.0
+
+const queryStringSyntax = `+-=&|><!(){}[]^"~*?:\/`
+
+// IsSimpleQuery reports whether text is plain words without any query string syntax.
+func IsSimpleQuery(text string) bool {
+	return strings.TrimSpace(text) != "" && !strings.ContainsAny(text, queryStringSyntax)
+}
+
+// NewTypoQuery matches every word exactly or within the edit distance the index tolerates for its length.
+func NewTypoQuery(text, field string, and bool, config *models.IndexMapConfig) query.Query {
+	fuzzyFields := typoFields(field, config)
+	words := strings.Fields(text)
+	clauses := make([]query.Query, 0, len(words))
+	for _, word := range words {
+		exact := bleve.NewMatchQuery(word)
+		exact.SetField(field)
+		exact.SetBoost(exactMatchBoost)
+		alternatives := []query.Query{exact}
+		if fuzziness := config.TypoTolerance.Fuzziness(word); fuzziness > 0 {
+			for _, f := range fuzzyFields {
+				fuzzy := bleve.NewMatchQuery(word)
+				fuzzy.SetField(f)
+				fuzzy.SetFuzziness(fuzziness)
+				alternatives = append(alternatives, fuzzy)
+			}
+		}
+		clauses = append(clauses, bleve.NewDisjunctionQuery(alternatives...))
+	}
+	if and {
+		return bleve.NewConjunctionQuery(clauses...)
+	}
+	return bleve.NewDisjunctionQuery(clauses...)
+}
+
+// TypoEnabled reports whether the index tolerates typos in simple searches.
+func TypoEnabled(config *models.IndexMapConfig) bool {
+	return config != nil && config.TypoTolerance != nil && config.TypoTolerance.Enabled
+}
+
+// typoFields lists the fields a word may be fuzzily matched on, an empty name stands for the composite field.
+func typoFields(field string, config *models.IndexMapConfig) []string {
+	typo := config.TypoTolerance
+	if field != "" {
+		if typo.IsDisabledOn(field) {
+			return nil
+		}
+		return []string{field}
+	}
+	if len(typo.DisableOnFields) == 0 {
+		return []string{""}
+	}
+	fields := make([]string, 0)
+	for _, searchable := range config.Searchable {
+		if searchable.IsText() && !typo.IsDisabledOn(searchable.Field) {
+			fields = append(fields, searchable.Field)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"Scout.go/internal"
+	"Scout.go/log"
+	"Scout.go/models"
+	"Scout.go/reg"
+	"Scout.go/util"
+	"go.uber.org/zap"
+	"time"
+)
+
+func PutAlias(alias string, payload models.AliasConfig) (models.IndexAlias, error) {
+	start := time.Now()
+
+	if err := reg.SetAlias(alias, payload.Indexes); err != nil {
+		return models.IndexAlias{}, err
+	}
+	if err := putAlias(alias, payload.Indexes); err != nil {
+		return models.IndexAlias{}, err
+	}
+	return models.IndexAlias{Alias: alias, Indexes: payload.Indexes, Execution: util.Elapsed(start)}, nil
+}
+
+func GetAlias(alias string) (models.IndexAlias, error) {
+	start := time.Now()
+
+	indexes, err := reg.AliasByName(alias)
+	if err != nil {
+		return models.IndexAlias{}, err
+	}
+	return models.IndexAlias{Alias: alias, Indexes: indexes, Execution: util.Elapsed(start)}, nil
+}
+
+func GetAliases() models.IndexAliases {
+	start := time.Now()
+
+	return models.IndexAliases{Aliases: reg.Aliases(), Execution: util.Elapsed(start)}
+}
+
+func DeleteAlias(alias string) (models.IndexAlias, error) {
+	start := time.Now()
+
+	if err := reg.RemoveAlias(alias); err != nil {
+		return models.IndexAlias{}, err
+	}
+	if err := internal.DB.Delete(alias, internal.AliasStore); err != nil {
+		log.AppLog.E(alias, "error deleting alias", zap.Error(err))
+		return models.IndexAlias{}, err
+	}
+	return models.IndexAlias{Alias: alias, Indexes: make([]string, 0), Execution: util.Elapsed(start)}, nil
+}
+
+// SwapAlias moves the alias from one index to another, e.g. to a freshly built copy of it.
+func SwapAlias(alias string, payload models.AliasSwap) (models.IndexAlias, error) {
+	start := time.Now()
+
+	indexes, err := reg.SwapAlias(alias, payload.From, payload.To)
+	if err != nil {
+		return models.IndexAlias{}, err
+	}
+	if err := putAlias(alias, indexes); err != nil {
+		return models.IndexAlias{}, err
+	}
+	return models.IndexAlias{Alias: alias, Indexes: indexes, Execution: util.Elapsed(start)}, nil
+}
+
+func putAlias(alias string, indexes []string) error {
+	err := internal.DB.PutMap(alias, &models.IndexAlias{Alias: alias, Indexes: indexes}, internal.AliasStore)
+	if err != nil {
+		log.AppLog.E(alias, "error putting alias", zap.Error(err))
+	}
+	return err
+}
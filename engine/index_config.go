@@ -1,13 +1,17 @@
 package engine
 
 import (
+	"Scout.go/errors"
 	"Scout.go/internal"
 	"Scout.go/log"
+	scoutmap "Scout.go/mapping"
 	"Scout.go/models"
 	"Scout.go/reg"
 	"Scout.go/storage"
 	"Scout.go/util"
+	"github.com/blevesearch/bleve/v2/mapping"
 	"go.uber.org/zap"
+	"os"
 	"time"
 )
 
@@ -19,6 +23,23 @@ func NewIndexConfig(payload models.IndexMapConfig) (models.IndexConfigResponse,
 			return models.IndexConfigResponse{}, err
 		}
 	}
+	if _, err := reg.AliasByName(payload.Index); err == nil {
+		return models.IndexConfigResponse{}, errors.ErrIndexIsAlias
+	}
+	// reject analyzers or tokenizers bleve does not know before the config is persisted
+	if _, err := scoutmap.NewIndexMapping(&payload); err != nil {
+		return models.IndexConfigResponse{}, err
+	}
+	// sorting, autocomplete and highlighting read the searchable fields, a raw mapping in use has to define them
+	if raw, err := internal.DB.Get(payload.Index, internal.MappingStore); err == nil {
+		mapper, err := scoutmap.NewIndexMappingFromBytes(raw)
+		if err != nil {
+			return models.IndexConfigResponse{}, err
+		}
+		if err := scoutmap.CheckMapping(mapper, &payload); err != nil {
+			return models.IndexConfigResponse{}, err
+		}
+	}
 
 	var status models.IndexConfigResponse
 	status.Message = "not reindexing as fields are same"
@@ -33,22 +54,35 @@ func NewIndexConfig(payload models.IndexMapConfig) (models.IndexConfigResponse,
 	}
 	if prevRec.Index != "" {
 		// Compare with new searchable
-		var tmpRec models.IndexMapConfig
-		tmpRec.Index = prevRec.Index
-		needReindex = tmpRec.IsDifferent(&payload)
+		needReindex = prevRec.IsDifferent(&payload)
 	} else {
 		needReindex = true
 	}
 
-	if needReindex {
-		updateIdxErr := UpdateIndex(&payload)
-		err = internal.DB.PutMap(payload.Index, &payload, internal.IndexConfigStore)
+	commit := func() error {
+		err := internal.DB.PutMap(payload.Index, &payload, internal.IndexConfigStore)
 		if err != nil {
 			log.AppLog.E(payload.Index, "error putting index config", zap.Error(err))
 		}
-		status.Status = updateIdxErr == nil
+		return err
+	}
+	if needReindex {
+		// the config is only saved once the index runs with its mapping, a failed rebuild keeps the old one
+		mapper, err := storage.IndexMapping(&payload)
+		if err != nil {
+			return models.IndexConfigResponse{}, err
+		}
+		if err := UpdateIndex(&payload, mapper, commit); err != nil {
+			return models.IndexConfigResponse{}, err
+		}
 		status.Message = "reindexing based on new fields"
+	} else {
+		// scoring, typo tolerance and highlight are read per query, they are saved even when the mapping stays
+		if err := commit(); err != nil {
+			return models.IndexConfigResponse{}, err
+		}
 	}
+	status.Status = true
 
 	status.Execution = util.Elapsed(start)
 	status.Index = payload.Index
@@ -56,65 +90,60 @@ func NewIndexConfig(payload models.IndexMapConfig) (models.IndexConfigResponse,
 	return status, nil
 }
 
-func UpdateIndex(mapConfig *models.IndexMapConfig) error {
-	_, err := reg.IndexByName(mapConfig.Index)
+// UpdateIndex creates the index on first use, an existing index is rebuilt in the background with the
+// mapping. commit persists what the mapping was built from once the index runs with it, nil when
+// nothing changed.
+func UpdateIndex(mapConfig *models.IndexMapConfig, mapper *mapping.IndexMappingImpl, commit func() error) error {
+	index, err := reg.IndexByName(mapConfig.Index)
 	if err != nil {
 		log.AppLog.E(mapConfig.Index, err.Error())
-		index, err := storage.NewIndex(mapConfig)
+		index, err := storage.NewIndexUsing(mapConfig.Index, mapper)
 		if err != nil {
 			log.AppLog.E(mapConfig.Index, err.Error())
 			return err
 		}
-		//ReIndex(index)
+		if commit != nil {
+			if err := commit(); err != nil {
+				_ = index.Close()
+				_ = os.RemoveAll(index.Path())
+				return err
+			}
+		}
 		reg.RegisterType(mapConfig.Index, index)
-	} else {
-		//ReIndex(index)
+		return nil
+	}
+	if err := index.StartReindex(mapper, commit); err != nil {
+		log.AppLog.E(mapConfig.Index, err.Error())
+		return err
 	}
 	return nil
 }
 
-/*func ReIndex(index *storage.Index) {
-	query := bleve.NewMatchAllQuery()
-	searchRequest := bleve.NewSearchRequest(query)
-	oldDocs, err := index.Search(searchRequest)
+// Reindex rebuilds the index from its stored config, e.g. after an analyzer changed in place.
+func Reindex(index string) (models.ReindexStatus, error) {
+	var config models.IndexMapConfig
+	if err := internal.DB.GetMap(index, &config, internal.IndexConfigStore); err != nil {
+		return models.ReindexStatus{}, err
+	}
+	mapper, err := storage.IndexMapping(&config)
 	if err != nil {
-		log.AppLog.Error(fmt.Sprintf("error searching bleve index: %s", err.Error()))
-	} else {
-		tmpKv := internal.NewTempDisk(index.Name())
-		for _, hit := range oldDocs.Hits {
-			doc, err := index.Get(hit.ID)
-			if err != nil {
-				log.AppLog.Error(fmt.Sprintf("error fetching document %s: %v", hit.ID, err))
-				continue
-			}
-			marshalled, err := json.Marshal(doc)
-			if err != nil {
-				log.AppLog.Error(fmt.Sprintf("error marshalling document %s: %v", hit.ID, err))
-				continue
-			}
-			putErr := tmpKv.Put(index.Name()+":"+string(hit.ID), marshalled)
-			if putErr != nil {
-				log.AppLog.Error(fmt.Sprintf("error putting document %s %s: %v", index.Name(), hit.ID, putErr))
-			}
-		}
-
-		data := make(chan map[string]interface{})
-		var wg sync.WaitGroup
-		wg.Add(1)
-		go tmpKv.Prefix(index.Name()+":", data, &wg)
-
-		for i := 0; i < 4; i++ {
-			wg.Add(1)
-			go func(workerID int) {
-				defer wg.Done()
-				for pair := range data {
-					key := pair["key"].(string)
-					value := pair["value"]
+		return models.ReindexStatus{}, err
+	}
+	if err := UpdateIndex(&config, mapper, nil); err != nil {
+		return models.ReindexStatus{}, err
+	}
+	return ReindexStatus(index)
+}
 
-				}
-			}(i)
-		}
+// ReindexStatus reports how far the running or last reindex got.
+func ReindexStatus(index string) (models.ReindexStatus, error) {
+	start := time.Now()
 
-		wg.Wait()
+	idx, err := reg.IndexByName(index)
+	if err != nil {
+		return models.ReindexStatus{}, err
 	}
-}*/
+	status := idx.ReindexStatus()
+	status.Execution = util.Elapsed(start)
+	return status, nil
+}
@@ -1 +1,44 @@
 package engine
+
+import (
+	"Scout.go/internal"
+	"Scout.go/models"
+	"Scout.go/reg"
+	"Scout.go/util"
+	"time"
+)
+
+// Count counts the documents of an index or alias, narrowed down by the query when one is given.
+func Count(index string, clause *models.QueryClause) (models.IndexRecordCount, error) {
+	start := time.Now()
+
+	idx, err := reg.SearchIndex(index)
+	if err != nil {
+		return models.IndexRecordCount{}, err
+	}
+	count, err := idx.Count(clause)
+	if err != nil {
+		return models.IndexRecordCount{}, err
+	}
+	return models.IndexRecordCount{Index: index, Count: count, Execution: util.Elapsed(start)}, nil
+}
+
+func Statistics(index string) (models.IndexStatistics, error) {
+	start := time.Now()
+
+	idx, err := reg.IndexByName(index)
+	if err != nil {
+		return models.IndexStatistics{}, err
+	}
+	stats, err := idx.Statistics()
+	if err != nil {
+		return models.IndexStatistics{}, err
+	}
+	var position models.BinlogPosition
+	// an index that is not fed from a binlog has no position
+	if err := internal.DB.GetMap(index, &position, internal.BinlogStore); err == nil {
+		stats.Binlog = &position
+	}
+	stats.Execution = util.Elapsed(start)
+	return stats, nil
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"Scout.go/internal"
+	"Scout.go/models"
+	"testing"
+	"time"
+)
+
+func TestCount(t *testing.T) {
+	newTestIndex(t, "counted", map[string]interface{}{"id": "1", "title": "red shoe"},
+		map[string]interface{}{"id": "2", "title": "blue shoe"}, map[string]interface{}{"id": "3", "title": "boot"})
+	if _, err := PutAlias("counted_alias", models.AliasConfig{Indexes: []string{"counted"}}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _, _ = DeleteAlias("counted_alias") })
+
+	tests := []struct {
+		name, index string
+		clause      *models.QueryClause
+		want        uint64
+	}{
+		{"every document", "counted", nil, 3},
+		{"matching documents", "counted", &models.QueryClause{Match: &models.MatchClause{Field: "title", Query: "shoe"}}, 2},
+		{"through an alias", "counted_alias", &models.QueryClause{QueryString: "boot"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Count(tt.index, tt.clause)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Count != tt.want {
+				t.Errorf("Count() = %d, want %d", res.Count, tt.want)
+			}
+		})
+	}
+	if _, err := Count("nope", nil); err == nil {
+		t.Error("counting a missing index succeeded")
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	before := time.Now().Add(-time.Second)
+	newTestIndex(t, "measured", map[string]interface{}{"id": "1", "title": "shoe"}, map[string]interface{}{"id": "2", "title": "boot"})
+
+	stats, err := Statistics("measured")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.DocCount != 2 || stats.Reindex != models.ReindexIdle {
+		t.Errorf("Statistics() = %+v, want 2 documents and no reindex", stats)
+	}
+	if stats.LastIndexed == nil || stats.LastIndexed.Before(before) {
+		t.Errorf("last indexed = %v, want after %v", stats.LastIndexed, before)
+	}
+	if stats.Binlog != nil {
+		t.Errorf("an index without a binlog reports position %v", stats.Binlog)
+	}
+
+	position := models.BinlogPosition{Name: "mysql-bin.000003", Pos: 1200}
+	if err := internal.DB.PutMap("measured", &position, internal.BinlogStore); err != nil {
+		t.Fatal(err)
+	}
+	stats, err = Statistics("measured")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Binlog == nil || stats.Binlog.Name != position.Name || stats.Binlog.Pos != position.Pos {
+		t.Errorf("binlog position = %v, want %v", stats.Binlog, position)
+	}
+}
@@ -1 +1,103 @@
 package engine
+
+import (
+	"Scout.go/event"
+	"Scout.go/internal"
+	"Scout.go/log"
+	"Scout.go/models"
+	"Scout.go/reg"
+	"Scout.go/util"
+	"fmt"
+	"go.uber.org/zap"
+	"os"
+	"strings"
+	"time"
+)
+
+// DeleteIndex closes the index, removes it from disk and drops everything stored for it. The binlog
+// watchman of the index is stopped and the index is taken out of any alias.
+func DeleteIndex(index string) (models.IndexDeletion, error) {
+	start := time.Now()
+
+	idx, err := reg.UnregisterType(index)
+	if err != nil {
+		return models.IndexDeletion{}, err
+	}
+	// closing cancels a running reindex first, the path is read after it so a finished swap is honoured
+	if err := idx.Close(); err != nil {
+		log.AppLog.E(index, "error closing index", zap.Error(err))
+	}
+	dir := idx.Path()
+	if err := os.RemoveAll(dir); err != nil {
+		log.AppLog.E(index, "error removing index directory", zap.String("dir", dir), zap.Error(err))
+		return models.IndexDeletion{}, err
+	}
+
+	var dbCnf models.DbConfig
+	if err := internal.DB.GetMap(index, &dbCnf, internal.DbConfigStore); err == nil {
+		// first time fetch markers, a new index under the same name has to fetch the tables again
+		for _, table := range strings.Split(dbCnf.WatchTable, ",") {
+			_ = internal.DB.Delete(fmt.Sprintf("completed:%s:%s", dbCnf.Database, table), "")
+		}
+		if event.PubSubChannel != nil {
+			event.PubSubChannel.Publish("db-cnf", &models.IndexRemoved{Index: index})
+		}
+	}
+	stores := []string{internal.IndexConfigStore, internal.DbConfigStore, internal.SynonymStore, internal.RuleStore, internal.MappingStore, internal.IndexPathStore, internal.BinlogStore}
+	for _, store := range stores {
+		if err := internal.DB.Delete(index, store); err != nil {
+			log.AppLog.E(index, "error deleting index record", zap.String("store", store), zap.Error(err))
+		}
+	}
+	for alias, indexes := range reg.DropAliasMember(index) {
+		if len(indexes) == 0 {
+			_ = internal.DB.Delete(alias, internal.AliasStore)
+			continue
+		}
+		_ = putAlias(alias, indexes)
+	}
+	if !internal.IsStoreBucket(index) {
+		dropLogBucket(internal.DB, index)
+		dropLogBucket(internal.LogDb, index)
+	}
+
+	return models.IndexDeletion{Index: index, Status: true, Execution: util.Elapsed(start)}, nil
+}
+
+func dropLogBucket(db *internal.TempDisk, index string) {
+	if exists, _ := db.BucketExists(index); !exists {
+		return
+	}
+	if err := db.DropBucket(index); err != nil {
+		log.AppLog.Error("error dropping log bucket", zap.String("index", index), zap.Error(err))
+	}
+}
+
+func DeleteDocument(index, id string) (models.IndexDataDeletion, error) {
+	start := time.Now()
+
+	idx, err := reg.WriteIndex(index)
+	if err != nil {
+		return models.IndexDataDeletion{}, err
+	}
+	if _, err := idx.Get(id); err != nil {
+		return models.IndexDataDeletion{}, err
+	}
+	if err := idx.Delete(id); err != nil {
+		return models.IndexDataDeletion{}, err
+	}
+	return models.IndexDataDeletion{Index: index, Status: true, Uid: id, Execution: util.Elapsed(start)}, nil
+}
+
+func DeleteDocuments(index string, ids []string) (models.IndexDataBatchDeletion, error) {
+	start := time.Now()
+
+	idx, err := reg.WriteIndex(index)
+	if err != nil {
+		return models.IndexDataBatchDeletion{}, err
+	}
+	if _, err := idx.BulkDelete(ids); err != nil {
+		return models.IndexDataBatchDeletion{}, err
+	}
+	return models.IndexDataBatchDeletion{Index: index, Uid: ids, Execution: util.Elapsed(start)}, nil
+}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"Scout.go/internal"
+	"Scout.go/models"
+	"Scout.go/reg"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestMain keeps the config store and the test indexes in a temporary working directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "scout-engine")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	internal.NewDiskStorage()
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// newTestIndex configures and registers an index with a string title, it is deleted after the test.
+func newTestIndex(t *testing.T, name string, docs ...map[string]interface{}) {
+	t.Helper()
+	config := models.IndexMapConfig{Index: name, UniqueId: "id", Searchable: []models.IndexSearchable{{Field: "title", Type: models.String}}}
+	if _, err := NewIndexConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _, _ = DeleteIndex(name) })
+	if len(docs) > 0 {
+		if _, err := UpsertDocuments(name, docs); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDeleteIndex(t *testing.T) {
+	newTestIndex(t, "deleted", map[string]interface{}{"id": "1", "title": "shoe"})
+	newTestIndex(t, "kept")
+	index, err := reg.IndexByName("deleted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := index.Path()
+	if _, err := PutSynonyms("deleted", models.SynonymSet{Groups: [][]string{{"shoe", "sneaker"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PutAlias("only", models.AliasConfig{Indexes: []string{"deleted"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PutAlias("both", models.AliasConfig{Indexes: []string{"deleted", "kept"}}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _, _ = DeleteAlias("both") })
+
+	if res, err := DeleteIndex("deleted"); err != nil || !res.Status {
+		t.Fatalf("DeleteIndex() = %v, %v", res, err)
+	}
+	if _, err := reg.IndexByName("deleted"); err == nil {
+		t.Error("the index is still registered")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("index directory %s was kept", dir)
+	}
+	for _, store := range []string{internal.IndexConfigStore, internal.SynonymStore} {
+		if _, err := internal.DB.Get("deleted", store); err == nil {
+			t.Errorf("%s still holds the index", store)
+		}
+	}
+	if _, err := GetAlias("only"); err == nil {
+		t.Error("an alias of the deleted index alone was kept")
+	}
+	if alias, err := GetAlias("both"); err != nil || !reflect.DeepEqual(alias.Indexes, []string{"kept"}) {
+		t.Errorf("alias both = %v, %v, want [kept]", alias.Indexes, err)
+	}
+	if _, err := DeleteIndex("deleted"); err == nil {
+		t.Error("deleting the index twice succeeded")
+	}
+
+	// the name is free for a new index that starts out empty
+	newTestIndex(t, "deleted")
+	if _, err := GetDocument("deleted", "1"); err == nil {
+		t.Error("a new index under the name found a document of the deleted one")
+	}
+}
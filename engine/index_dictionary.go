@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"Scout.go/internal"
+	"Scout.go/models"
+	"Scout.go/util"
+	"time"
+)
+
+// PutDictionary stores the stop and protected words on the index config and rebuilds the index with
+// analyzers compiled from them.
+func PutDictionary(index string, dictionary models.Dictionary) (models.IndexDictionary, error) {
+	start := time.Now()
+
+	var config models.IndexMapConfig
+	if err := internal.DB.GetMap(index, &config, internal.IndexConfigStore); err != nil {
+		return models.IndexDictionary{}, err
+	}
+	config.Dictionary = &dictionary
+	status, err := NewIndexConfig(config)
+	if err != nil {
+		return models.IndexDictionary{}, err
+	}
+	return models.IndexDictionary{Index: index, Dictionary: config.Dictionary, Execution: util.Elapsed(start), Message: status.Message}, nil
+}
+
+func GetDictionary(index string) (models.IndexDictionary, error) {
+	start := time.Now()
+
+	var config models.IndexMapConfig
+	if err := internal.DB.GetMap(index, &config, internal.IndexConfigStore); err != nil {
+		return models.IndexDictionary{}, err
+	}
+	return models.IndexDictionary{Index: index, Dictionary: config.Dictionary, Execution: util.Elapsed(start)}, nil
+}
@@ -1 +1,117 @@
 package engine
+
+import (
+	"Scout.go/errors"
+	"Scout.go/models"
+	"Scout.go/reg"
+	"Scout.go/storage"
+	"Scout.go/util"
+	"fmt"
+	"time"
+)
+
+// UpsertDocument indexes the document under the id, the unique id field is filled from the id when missing.
+func UpsertDocument(index, id string, doc map[string]interface{}) (models.IndexInsertion, error) {
+	start := time.Now()
+
+	idx, config, err := writeIndex(index)
+	if err != nil {
+		return models.IndexInsertion{}, err
+	}
+	if _, ok := doc[config.UniqueId]; !ok {
+		doc[config.UniqueId] = id
+	}
+	uid, err := documentId(doc, config)
+	if err != nil {
+		return models.IndexInsertion{}, err
+	}
+	if uid != id {
+		return models.IndexInsertion{}, errors.ErrUniqueIdMismatch
+	}
+	if _, err := idx.PrepareAndIndex([]map[string]interface{}{doc}); err != nil {
+		return models.IndexInsertion{}, err
+	}
+	return models.IndexInsertion{Index: index, Uid: id, Status: true, Execution: util.Elapsed(start)}, nil
+}
+
+// UpsertDocuments indexes a batch, every document has to carry the unique id field of the index.
+func UpsertDocuments(index string, docs []map[string]interface{}) (models.IndexBatchInsertion, error) {
+	start := time.Now()
+
+	idx, config, err := writeIndex(index)
+	if err != nil {
+		return models.IndexBatchInsertion{}, err
+	}
+	if len(docs) == 0 {
+		return models.IndexBatchInsertion{}, errors.ErrNoUpdate
+	}
+	for n, doc := range docs {
+		if _, err := documentId(doc, config); err != nil {
+			return models.IndexBatchInsertion{}, fmt.Errorf("document %d: %w", n, err)
+		}
+	}
+	// repeated ids collapse into one document, the count is what was actually indexed
+	count, err := idx.PrepareAndIndex(docs)
+	if err != nil {
+		return models.IndexBatchInsertion{}, err
+	}
+	return models.IndexBatchInsertion{Index: index, Count: uint32(count), Execution: util.Elapsed(start)}, nil
+}
+
+func GetDocument(index, id string) (models.IndexDocument, error) {
+	start := time.Now()
+
+	idx, err := reg.SearchIndex(index)
+	if err != nil {
+		return models.IndexDocument{}, err
+	}
+	doc, err := idx.Get(id)
+	if err != nil {
+		return models.IndexDocument{}, err
+	}
+	version, err := idx.Version(id)
+	if err != nil {
+		return models.IndexDocument{}, err
+	}
+	return models.IndexDocument{Index: index, Uid: id, Version: version, Document: doc, Execution: util.Elapsed(start)}, nil
+}
+
+// UpdateDocument merges the fields into the stored document, optionally only at the expected version.
+func UpdateDocument(index, id string, payload models.DocumentUpdate) (models.IndexDocument, error) {
+	start := time.Now()
+
+	idx, err := reg.WriteIndex(index)
+	if err != nil {
+		return models.IndexDocument{}, err
+	}
+	version, doc, err := idx.Update(id, payload.Doc, payload.Version)
+	if err != nil {
+		return models.IndexDocument{}, err
+	}
+	return models.IndexDocument{Index: index, Uid: id, Version: version, Document: doc, Execution: util.Elapsed(start)}, nil
+}
+
+func writeIndex(index string) (*storage.Index, *models.IndexMapConfig, error) {
+	idx, err := reg.WriteIndex(index)
+	if err != nil {
+		return nil, nil, err
+	}
+	config, err := idx.Config()
+	if err != nil {
+		return nil, nil, err
+	}
+	return idx, config, nil
+}
+
+// documentId reads the unique id field the same way indexing does.
+func documentId(doc map[string]interface{}, config *models.IndexMapConfig) (string, error) {
+	v, ok := doc[config.UniqueId]
+	if !ok {
+		return "", errors.ErrMissingUniqueId
+	}
+	uid, err := util.ToString(v)
+	if err != nil || uid == "" {
+		return "", errors.ErrMissingUniqueId
+	}
+	return uid, nil
+}
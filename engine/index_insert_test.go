@@ -0,0 +1,66 @@
+package engine
+
+import (
+	yrr "Scout.go/errors"
+	"errors"
+	"testing"
+)
+
+func TestDocumentCRUD(t *testing.T) {
+	newTestIndex(t, "crud")
+
+	if _, err := UpsertDocument("crud", "1", map[string]interface{}{"title": "red shoe"}); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := GetDocument("crud", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Document["title"] != "red shoe" || doc.Document["id"] != "1" || doc.Version == 0 {
+		t.Errorf("GetDocument() = %+v, want the title, the id filled in and a version", doc)
+	}
+	if _, err := UpsertDocument("crud", "1", map[string]interface{}{"title": "blue shoe"}); err != nil {
+		t.Fatal(err)
+	}
+	if updated, err := GetDocument("crud", "1"); err != nil || updated.Document["title"] != "blue shoe" || updated.Version <= doc.Version {
+		t.Errorf("after an upsert GetDocument() = %+v, %v", updated, err)
+	}
+	if _, err := UpsertDocument("crud", "1", map[string]interface{}{"id": "2", "title": "boot"}); !errors.Is(err, yrr.ErrUniqueIdMismatch) {
+		t.Errorf("an id that disagrees with the path gave %v", err)
+	}
+
+	batch := []map[string]interface{}{{"id": "2", "title": "boot"}, {"id": "3", "title": "sock"}, {"id": "3", "title": "sandal"}}
+	if res, err := UpsertDocuments("crud", batch); err != nil || res.Count != 2 {
+		t.Errorf("UpsertDocuments() = %+v, %v, want a count of 2", res, err)
+	}
+	if doc, err := GetDocument("crud", "3"); err != nil || doc.Document["title"] != "sandal" {
+		t.Errorf("a repeated id kept %v, %v, want the last version", doc.Document, err)
+	}
+	if _, err := UpsertDocuments("crud", []map[string]interface{}{{"id": "4"}, {"title": "no id"}}); !errors.Is(err, yrr.ErrMissingUniqueId) {
+		t.Errorf("a batch with a document without id gave %v", err)
+	}
+	if _, err := GetDocument("crud", "4"); err == nil {
+		t.Error("a rejected batch was partly indexed")
+	}
+
+	if _, err := DeleteDocument("crud", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetDocument("crud", "1"); err == nil {
+		t.Error("a deleted document is still returned")
+	}
+	if _, err := DeleteDocument("crud", "1"); err == nil {
+		t.Error("deleting a missing document succeeded")
+	}
+	if _, err := DeleteDocuments("crud", []string{"2", "3"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"2", "3"} {
+		if _, err := GetDocument("crud", id); err == nil {
+			t.Errorf("document %s survived the batch delete", id)
+		}
+	}
+	if _, err := UpsertDocument("nope", "1", map[string]interface{}{}); !errors.Is(err, yrr.ErrRegNotFound) {
+		t.Errorf("upserting into a missing index gave %v", err)
+	}
+}
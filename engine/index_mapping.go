@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"Scout.go/internal"
+	"Scout.go/log"
+	scoutmap "Scout.go/mapping"
+	"Scout.go/models"
+	"Scout.go/reg"
+	"Scout.go/util"
+	"encoding/json"
+	"go.uber.org/zap"
+	"time"
+)
+
+// PutMapping stores a complete bleve index mapping for the index, it replaces the mapping generated
+// from the searchable fields and the index is rebuilt with it.
+func PutMapping(index string, raw []byte) (models.IndexMappingResponse, error) {
+	start := time.Now()
+
+	var config models.IndexMapConfig
+	if err := internal.DB.GetMap(index, &config, internal.IndexConfigStore); err != nil {
+		return models.IndexMappingResponse{}, err
+	}
+	mapper, err := scoutmap.NewIndexMappingFromBytes(raw)
+	if err != nil {
+		return models.IndexMappingResponse{}, err
+	}
+	// the features driven by the searchable fields keep working only when the mapping defines their fields
+	if err := scoutmap.CheckMapping(mapper, &config); err != nil {
+		return models.IndexMappingResponse{}, err
+	}
+	// the mapping is only stored once the index runs with it
+	commit := func() error {
+		err := internal.DB.PutJson(index, string(raw), internal.MappingStore)
+		if err != nil {
+			log.AppLog.E(index, "error putting index mapping", zap.Error(err))
+		}
+		return err
+	}
+	if err := UpdateIndex(&config, mapper, commit); err != nil {
+		return models.IndexMappingResponse{}, err
+	}
+	return models.IndexMappingResponse{Index: index, Status: true, Mapping: json.RawMessage(raw), Execution: util.Elapsed(start)}, nil
+}
+
+// GetMapping returns the stored raw mapping, or the mapping the index is currently running with.
+func GetMapping(index string) (models.IndexMappingResponse, error) {
+	start := time.Now()
+
+	if raw, err := internal.DB.GetJson(index, internal.MappingStore); err == nil {
+		return models.IndexMappingResponse{Index: index, Status: true, Mapping: json.RawMessage(raw), Execution: util.Elapsed(start)}, nil
+	}
+	idx, err := reg.IndexByName(index)
+	if err != nil {
+		return models.IndexMappingResponse{}, err
+	}
+	raw, err := json.Marshal(idx.Mapping())
+	if err != nil {
+		return models.IndexMappingResponse{}, err
+	}
+	return models.IndexMappingResponse{Index: index, Status: true, Mapping: raw, Execution: util.Elapsed(start)}, nil
+}
+
+// DeleteMapping drops the raw mapping so the index goes back to the mapping generated from its config.
+func DeleteMapping(index string) (models.IndexMappingResponse, error) {
+	start := time.Now()
+
+	var config models.IndexMapConfig
+	if err := internal.DB.GetMap(index, &config, internal.IndexConfigStore); err != nil {
+		return models.IndexMappingResponse{}, err
+	}
+	mapper, err := scoutmap.NewIndexMapping(&config)
+	if err != nil {
+		return models.IndexMappingResponse{}, err
+	}
+	commit := func() error {
+		err := internal.DB.Delete(index, internal.MappingStore)
+		if err != nil {
+			log.AppLog.E(index, "error deleting index mapping", zap.Error(err))
+		}
+		return err
+	}
+	if err := UpdateIndex(&config, mapper, commit); err != nil {
+		return models.IndexMappingResponse{}, err
+	}
+	return models.IndexMappingResponse{Index: index, Status: true, Execution: util.Elapsed(start)}, nil
+}
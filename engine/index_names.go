@@ -37,8 +37,10 @@ func IndexStats() models.IndexStats {
 
 	stats := make(map[string]map[string]interface{})
 
-	for k, v := range reg.Registry {
-		stats[k] = v.Stats()["index"].(map[string]interface{})
+	for k, v := range reg.Indexes() {
+		if indexStats, ok := v.Stats()["index"].(map[string]interface{}); ok {
+			stats[k] = indexStats
+		}
 	}
 	res.Stats = stats
 	res.Execution = util.Elapsed(start)
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"Scout.go/dsl"
+	"Scout.go/internal"
+	"Scout.go/log"
+	"Scout.go/models"
+	"Scout.go/reg"
+	"Scout.go/util"
+	"fmt"
+	"go.uber.org/zap"
+	"time"
+)
+
+func PutRules(index string, payload models.RuleSet) (models.IndexRules, error) {
+	start := time.Now()
+
+	if _, err := reg.IndexByName(index); err != nil {
+		return models.IndexRules{}, err
+	}
+	for _, rule := range payload.Rules {
+		from, err := dsl.ParseDate(rule.Start)
+		if err != nil {
+			return models.IndexRules{}, fmt.Errorf("rule %s: %v", rule.Id, err)
+		}
+		to, err := dsl.ParseDate(rule.End)
+		if err != nil {
+			return models.IndexRules{}, fmt.Errorf("rule %s: %v", rule.Id, err)
+		}
+		if rule.Start != "" && rule.End != "" && !to.After(from) {
+			return models.IndexRules{}, fmt.Errorf("rule %s: end must be after start", rule.Id)
+		}
+	}
+	if err := internal.DB.PutMap(index, &payload, internal.RuleStore); err != nil {
+		log.AppLog.E(index, "error putting rules", zap.Error(err))
+		return models.IndexRules{}, err
+	}
+	return models.IndexRules{Index: index, Rules: payload.Rules, Execution: util.Elapsed(start)}, nil
+}
+
+func GetRules(index string) (models.IndexRules, error) {
+	start := time.Now()
+
+	if _, err := reg.IndexByName(index); err != nil {
+		return models.IndexRules{}, err
+	}
+	var set models.RuleSet
+	// an index without rules simply has no entry in the store
+	_ = internal.DB.GetMap(index, &set, internal.RuleStore)
+	if set.Rules == nil {
+		set.Rules = make([]models.MerchandisingRule, 0)
+	}
+	return models.IndexRules{Index: index, Rules: set.Rules, Execution: util.Elapsed(start)}, nil
+}
+
+func DeleteRules(index string) (models.IndexRules, error) {
+	start := time.Now()
+
+	if _, err := reg.IndexByName(index); err != nil {
+		return models.IndexRules{}, err
+	}
+	if err := internal.DB.Delete(index, internal.RuleStore); err != nil {
+		log.AppLog.E(index, "error deleting rules", zap.Error(err))
+		return models.IndexRules{}, err
+	}
+	return models.IndexRules{Index: index, Rules: make([]models.MerchandisingRule, 0), Execution: util.Elapsed(start)}, nil
+}
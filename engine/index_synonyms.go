@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"Scout.go/internal"
+	"Scout.go/log"
+	"Scout.go/models"
+	"Scout.go/reg"
+	"Scout.go/util"
+	"go.uber.org/zap"
+	"time"
+)
+
+func PutSynonyms(index string, payload models.SynonymSet) (models.IndexSynonyms, error) {
+	start := time.Now()
+
+	if _, err := reg.IndexByName(index); err != nil {
+		return models.IndexSynonyms{}, err
+	}
+	if err := internal.DB.PutMap(index, &payload, internal.SynonymStore); err != nil {
+		log.AppLog.E(index, "error putting synonyms", zap.Error(err))
+		return models.IndexSynonyms{}, err
+	}
+	return models.IndexSynonyms{Index: index, Groups: payload.Groups, Execution: util.Elapsed(start)}, nil
+}
+
+func GetSynonyms(index string) (models.IndexSynonyms, error) {
+	start := time.Now()
+
+	if _, err := reg.IndexByName(index); err != nil {
+		return models.IndexSynonyms{}, err
+	}
+	var set models.SynonymSet
+	// an index without synonyms simply has no entry in the store
+	_ = internal.DB.GetMap(index, &set, internal.SynonymStore)
+	if set.Groups == nil {
+		set.Groups = make([][]string, 0)
+	}
+	return models.IndexSynonyms{Index: index, Groups: set.Groups, Execution: util.Elapsed(start)}, nil
+}
+
+func DeleteSynonyms(index string) (models.IndexSynonyms, error) {
+	start := time.Now()
+
+	if _, err := reg.IndexByName(index); err != nil {
+		return models.IndexSynonyms{}, err
+	}
+	if err := internal.DB.Delete(index, internal.SynonymStore); err != nil {
+		log.AppLog.E(index, "error deleting synonyms", zap.Error(err))
+		return models.IndexSynonyms{}, err
+	}
+	return models.IndexSynonyms{Index: index, Groups: make([][]string, 0), Execution: util.Elapsed(start)}, nil
+}
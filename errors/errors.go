@@ -23,4 +23,15 @@ var (
 	ErrIndexBatch       = errors.New("failed to batch index")
 	ErrNoWatchTable     = errors.New("no watch table")
 	ErrNoWatchDb        = errors.New("no watch db")
+	ErrHighlightOff     = errors.New("highlighting is not enabled for index")
+	ErrNoAutocomplete   = errors.New("no autocomplete field configured")
+	ErrReindexRunning   = errors.New("reindex is already running")
+	ErrReindexCancelled = errors.New("reindex was cancelled")
+	ErrAliasNotFound    = errors.New("alias not found")
+	ErrAliasIsIndex     = errors.New("alias name is already used by an index")
+	ErrAliasMember      = errors.New("index is not part of the alias")
+	ErrIndexIsAlias     = errors.New("index name is already used by an alias")
+	ErrMissingUniqueId  = errors.New("document is missing the unique id field")
+	ErrUniqueIdMismatch = errors.New("document unique id does not match the path")
+	ErrVersionConflict  = errors.New("document version does not match the expected version")
 )
@@ -15,9 +15,24 @@ import (
 const (
 	DbConfigStore    = "_db_config_"
 	IndexConfigStore = "_index_config_"
+	SynonymStore     = "_synonyms_"
+	RuleStore        = "_rules_"
+	MappingStore     = "_mappings_"
+	IndexPathStore   = "_index_paths_"
+	AliasStore       = "_aliases_"
+	BinlogStore      = "_binlog_positions_"
 	defaultBucket    = "_default_"
 )
 
+// IsStoreBucket reports whether the bucket holds Scout's own records rather than the logs of an index.
+func IsStoreBucket(bucket string) bool {
+	switch bucket {
+	case DbConfigStore, IndexConfigStore, SynonymStore, RuleStore, MappingStore, IndexPathStore, AliasStore, BinlogStore, defaultBucket:
+		return true
+	}
+	return false
+}
+
 type TempDisk struct {
 	store *bbolt.DB
 	dir   string
@@ -57,6 +72,36 @@ func NewDiskStorage() {
 			log.Error("create bucket error ", zap.String("bucket", DbConfigStore), zap.Error(err))
 			return err
 		}
+		_, err = tx.CreateBucketIfNotExists([]byte(SynonymStore))
+		if err != nil {
+			log.Error("create bucket error ", zap.String("bucket", SynonymStore), zap.Error(err))
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists([]byte(RuleStore))
+		if err != nil {
+			log.Error("create bucket error ", zap.String("bucket", RuleStore), zap.Error(err))
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists([]byte(MappingStore))
+		if err != nil {
+			log.Error("create bucket error ", zap.String("bucket", MappingStore), zap.Error(err))
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists([]byte(IndexPathStore))
+		if err != nil {
+			log.Error("create bucket error ", zap.String("bucket", IndexPathStore), zap.Error(err))
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists([]byte(AliasStore))
+		if err != nil {
+			log.Error("create bucket error ", zap.String("bucket", AliasStore), zap.Error(err))
+			return err
+		}
+		_, err = tx.CreateBucketIfNotExists([]byte(BinlogStore))
+		if err != nil {
+			log.Error("create bucket error ", zap.String("bucket", BinlogStore), zap.Error(err))
+			return err
+		}
 		return nil
 	})
 	if err != nil {
@@ -47,6 +47,17 @@ func main() {
 		reg.RegisterType(config.Index, index)
 	}
 
+	var aliases []models.IndexAlias
+	err = internal.DB.Find(&aliases, "", 0, internal.AliasStore)
+	if err != nil {
+		log.Printf("failed to boot aliases %v", err)
+	}
+	for _, alias := range aliases {
+		if err := reg.SetAlias(alias.Alias, alias.Indexes); err != nil {
+			log.Printf("failed to boot alias %s %v", alias.Alias, err)
+		}
+	}
+
 	event.PubSubChannel = event.InitPubSub()
 	wt := binlog.WatchDataChanges().Boot()
 	ps := event.PubSubChannel.Subscribe("db-cnf")
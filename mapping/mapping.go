@@ -3,51 +3,366 @@ package mapping
 import (
 	"Scout.go/models"
 	"encoding/json"
+	"fmt"
 	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
 	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/analysis/token/edgengram"
+	keywordmarker "github.com/blevesearch/bleve/v2/analysis/token/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/token/porter"
+	"github.com/blevesearch/bleve/v2/analysis/token/stop"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/whitespace"
+	"github.com/blevesearch/bleve/v2/analysis/tokenmap"
 	"github.com/blevesearch/bleve/v2/mapping"
 	"io/ioutil"
 	"os"
+	"strings"
 )
 
+const (
+	// SortSuffix names the untokenized sub-field string fields are sorted on.
+	SortSuffix = ".sort"
+	// AutocompleteSuffix names the edge-ngram sub-field used for type-ahead.
+	AutocompleteSuffix = ".autocomplete"
+	// AutocompleteSearchAnalyzer analyzes the typed prefix without ngrams.
+	AutocompleteSearchAnalyzer = "scout_autocomplete_search"
+
+	autocompleteAnalyzer = "scout_autocomplete"
+	autocompleteFilter   = "scout_edge_ngram"
+	whitespaceAnalyzer   = "whitespace"
+
+	// the dictionary filters can also be referenced from custom analyzers
+	dictionaryAnalyzer   = "scout_dictionary"
+	stopWordsFilter      = "scout_stop_words"
+	protectedWordsFilter = "scout_protected_words"
+)
+
+func SortField(field string) string {
+	return field + SortSuffix
+}
+
+func AutocompleteField(field string) string {
+	return field + AutocompleteSuffix
+}
+
 func NewIndexMapping(config *models.IndexMapConfig) (*mapping.IndexMappingImpl, error) {
+	mapper := mapping.NewIndexMapping()
 	docMap := bleve.NewDocumentMapping()
 
+	if err := addAutocompleteAnalyzers(mapper); err != nil {
+		return nil, err
+	}
+	if err := addDictionaryAnalyzer(mapper, config.Dictionary); err != nil {
+		return nil, err
+	}
+	if err := addCustomAnalyzers(mapper, config); err != nil {
+		return nil, err
+	}
+	defaultAnalyzer := defaultAnalyzerOf(config)
+
 	for _, searchable := range config.Searchable {
-		if searchable.Type == models.String {
+		if searchable.IsText() {
 			textFieldMapping := bleve.NewTextFieldMapping()
-			textFieldMapping.Analyzer = standard.Name
+			textFieldMapping.Analyzer = defaultAnalyzer
+			if searchable.Analyzer != "" {
+				textFieldMapping.Analyzer = searchable.Analyzer
+			}
 			textFieldMapping.Store = true
-			docMap.AddFieldMappingsAt(searchable.Field, textFieldMapping)
+			textFieldMapping.IncludeInAll = IncludedInAll(searchable, config)
+			// term vectors back both phrase queries and highlighting
+			textFieldMapping.IncludeTermVectors = true
+			textFieldMapping.DocValues = true
+			fieldMappings := []*mapping.FieldMapping{textFieldMapping, newSortFieldMapping(searchable.Field)}
+			if searchable.Autocomplete {
+				fieldMappings = append(fieldMappings, newAutocompleteFieldMapping(searchable.Field))
+			}
+			addFieldMappingsAt(docMap, searchable.Field, fieldMappings...)
+		}
+		if searchable.Type == models.Keyword {
+			keywordFieldMapping := bleve.NewKeywordFieldMapping()
+			keywordFieldMapping.Store = true
+			keywordFieldMapping.DocValues = true
+			addFieldMappingsAt(docMap, searchable.Field, keywordFieldMapping)
 		}
-		if searchable.Type == models.Number {
+		if searchable.Type == models.IP {
+			ipFieldMapping := bleve.NewIPFieldMapping()
+			ipFieldMapping.Store = true
+			ipFieldMapping.DocValues = true
+			addFieldMappingsAt(docMap, searchable.Field, ipFieldMapping)
+		}
+		if searchable.Type == models.Number || searchable.Type == models.NumberArray {
 			numericFieldMapping := bleve.NewNumericFieldMapping()
 			numericFieldMapping.Store = true
 			numericFieldMapping.DocValues = true
-			docMap.AddFieldMappingsAt(searchable.Field, numericFieldMapping)
+			addFieldMappingsAt(docMap, searchable.Field, numericFieldMapping)
 		}
 		if searchable.Type == models.Boolean {
 			boolFieldMapping := bleve.NewBooleanFieldMapping()
 			boolFieldMapping.Store = true
 			boolFieldMapping.DocValues = true
-			docMap.AddFieldMappingsAt(searchable.Field, boolFieldMapping)
+			addFieldMappingsAt(docMap, searchable.Field, boolFieldMapping)
+		}
+		if searchable.Type == models.GeoPoint {
+			geoPointFieldMapping := bleve.NewGeoPointFieldMapping()
+			geoPointFieldMapping.Store = true
+			geoPointFieldMapping.DocValues = true
+			// the lat / lon keys of a point are part of it, not fields of their own
+			addFieldMappingsAt(docMap, searchable.Field, geoPointFieldMapping).Dynamic = false
 		}
 		if searchable.Type == models.DateTime {
 			dateTimeFieldMapping := bleve.NewDateTimeFieldMapping()
 			dateTimeFieldMapping.Store = true
 			dateTimeFieldMapping.DocValues = true
-			docMap.AddFieldMappingsAt(searchable.Field, dateTimeFieldMapping)
+			addFieldMappingsAt(docMap, searchable.Field, dateTimeFieldMapping)
 		}
 	}
 
-	mapper := mapping.NewIndexMapping()
 	mapper.DefaultMapping = docMap
+	// searches on _all and unmapped fields analyze their text the way the string fields were indexed
+	mapper.DefaultAnalyzer = defaultAnalyzer
 	if err := mapper.Validate(); err != nil {
 		return nil, err
 	}
 	return mapper, nil
 }
 
+// IncludedInAll reports whether the text field is searched through the composite _all field. Searches on
+// _all analyze the words with the default analyzer, a field with an analyzer of its own is left out of it.
+func IncludedInAll(searchable models.IndexSearchable, config *models.IndexMapConfig) bool {
+	return searchable.Analyzer == "" || searchable.Analyzer == defaultAnalyzerOf(config)
+}
+
+func defaultAnalyzerOf(config *models.IndexMapConfig) string {
+	if config.Dictionary != nil {
+		return dictionaryAnalyzer
+	}
+	return standard.Name
+}
+
+// addFieldMappingsAt maps a dotted path such as attributes.color through nested sub-documents.
+func addFieldMappingsAt(docMap *mapping.DocumentMapping, path string, fms ...*mapping.FieldMapping) *mapping.DocumentMapping {
+	elements := strings.Split(path, ".")
+	current := docMap
+	for _, element := range elements[:len(elements)-1] {
+		sub, ok := current.Properties[element]
+		if !ok {
+			sub = bleve.NewDocumentMapping()
+			current.AddSubDocumentMapping(element, sub)
+		}
+		current = sub
+	}
+	current.AddFieldMappingsAt(elements[len(elements)-1], fms...)
+	return current.Properties[elements[len(elements)-1]]
+}
+
+// leafName is the last element of a dotted path, sub-field names are relative to their parent document.
+func leafName(path string) string {
+	return path[strings.LastIndex(path, ".")+1:]
+}
+
+func newSortFieldMapping(field string) *mapping.FieldMapping {
+	sortFieldMapping := bleve.NewTextFieldMapping()
+	sortFieldMapping.Name = SortField(leafName(field))
+	sortFieldMapping.Analyzer = keyword.Name
+	sortFieldMapping.Store = false
+	sortFieldMapping.IncludeInAll = false
+	sortFieldMapping.IncludeTermVectors = false
+	sortFieldMapping.DocValues = true
+	return sortFieldMapping
+}
+
+func newAutocompleteFieldMapping(field string) *mapping.FieldMapping {
+	autocompleteFieldMapping := bleve.NewTextFieldMapping()
+	autocompleteFieldMapping.Name = AutocompleteField(leafName(field))
+	autocompleteFieldMapping.Analyzer = autocompleteAnalyzer
+	autocompleteFieldMapping.Store = false
+	autocompleteFieldMapping.IncludeInAll = false
+	autocompleteFieldMapping.IncludeTermVectors = false
+	autocompleteFieldMapping.DocValues = false
+	return autocompleteFieldMapping
+}
+
+func addAutocompleteAnalyzers(mapper *mapping.IndexMappingImpl) error {
+	err := mapper.AddCustomTokenFilter(autocompleteFilter, map[string]interface{}{
+		"type": edgengram.Name,
+		"back": false,
+		"min":  1.0,
+		"max":  20.0,
+	})
+	if err != nil {
+		return err
+	}
+	err = mapper.AddCustomAnalyzer(autocompleteAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     unicode.Name,
+		"token_filters": []string{lowercase.Name, autocompleteFilter},
+	})
+	if err != nil {
+		return err
+	}
+	return mapper.AddCustomAnalyzer(AutocompleteSearchAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     unicode.Name,
+		"token_filters": []string{lowercase.Name},
+	})
+}
+
+// addDictionaryAnalyzer compiles the stop and protected words into token maps and an analyzer that
+// drops the stop words and marks protected words as keywords before the porter stemmer runs.
+func addDictionaryAnalyzer(mapper *mapping.IndexMappingImpl, dictionary *models.Dictionary) error {
+	if dictionary == nil {
+		return nil
+	}
+	err := mapper.AddCustomTokenMap(stopWordsFilter, map[string]interface{}{
+		"type":   tokenmap.Name,
+		"tokens": lowerTokens(dictionary.StopWords),
+	})
+	if err != nil {
+		return err
+	}
+	err = mapper.AddCustomTokenMap(protectedWordsFilter, map[string]interface{}{
+		"type":   tokenmap.Name,
+		"tokens": lowerTokens(dictionary.ProtectedWords),
+	})
+	if err != nil {
+		return err
+	}
+	err = mapper.AddCustomTokenFilter(stopWordsFilter, map[string]interface{}{
+		"type":           stop.Name,
+		"stop_token_map": stopWordsFilter,
+	})
+	if err != nil {
+		return err
+	}
+	err = mapper.AddCustomTokenFilter(protectedWordsFilter, map[string]interface{}{
+		"type":               keywordmarker.Name,
+		"keywords_token_map": protectedWordsFilter,
+	})
+	if err != nil {
+		return err
+	}
+	return mapper.AddCustomAnalyzer(dictionaryAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     unicode.Name,
+		"token_filters": []string{lowercase.Name, stopWordsFilter, protectedWordsFilter, porter.Name},
+	})
+}
+
+// lowerTokens matches the words against lower cased tokens, token maps only accept []interface{}.
+func lowerTokens(words []string) []interface{} {
+	tokens := make([]interface{}, 0, len(words))
+	for _, word := range words {
+		tokens = append(tokens, strings.ToLower(strings.TrimSpace(word)))
+	}
+	return tokens
+}
+
+// addCustomAnalyzers registers the analyzers defined on the index, plus a whitespace analyzer bleve lacks.
+func addCustomAnalyzers(mapper *mapping.IndexMappingImpl, config *models.IndexMapConfig) error {
+	err := mapper.AddCustomAnalyzer(whitespaceAnalyzer, map[string]interface{}{
+		"type":      custom.Name,
+		"tokenizer": whitespace.Name,
+	})
+	if err != nil {
+		return err
+	}
+	for _, analyzer := range config.Analyzers {
+		err := mapper.AddCustomAnalyzer(analyzer.Name, map[string]interface{}{
+			"type":          custom.Name,
+			"char_filters":  nonNil(analyzer.CharFilters),
+			"tokenizer":     analyzer.Tokenizer,
+			"token_filters": nonNil(analyzer.TokenFilters),
+		})
+		if err != nil {
+			return fmt.Errorf("analyzer %s: %v", analyzer.Name, err)
+		}
+	}
+	return nil
+}
+
+func nonNil(names []string) []string {
+	if names == nil {
+		return []string{}
+	}
+	return names
+}
+
+// CheckMapping makes sure a raw mapping defines what the features of the config read: every string field
+// as text, the autocomplete sub-field of autocomplete fields and term vectors when highlighting is on.
+// Typo tolerance and multi_match search the string fields themselves, sorting is checked by CheckSort.
+func CheckMapping(mapper *mapping.IndexMappingImpl, config *models.IndexMapConfig) error {
+	for _, searchable := range config.Searchable {
+		if !searchable.IsText() {
+			continue
+		}
+		leaf := leafName(searchable.Field)
+		fields := fieldMappingsAt(mapper.DefaultMapping, searchable.Field)
+		text := findFieldMapping(fields, leaf)
+		if text == nil {
+			// a field mapping without a name is indexed under the name of its property
+			text = findFieldMapping(fields, "")
+		}
+		if text == nil || text.Type != "text" {
+			return fmt.Errorf("raw mapping does not map %s as text", searchable.Field)
+		}
+		if config.Highlight && !text.IncludeTermVectors {
+			return fmt.Errorf("raw mapping does not keep term vectors on %s, highlighting needs them", searchable.Field)
+		}
+		if searchable.Autocomplete && findFieldMapping(fields, AutocompleteField(leaf)) == nil {
+			return fmt.Errorf("raw mapping does not define %s, autocomplete on %s needs it", AutocompleteField(searchable.Field), searchable.Field)
+		}
+	}
+	return nil
+}
+
+// CheckSort makes sure the string fields a search is sorted on have their sort sub-field, a raw mapping
+// may leave it out of fields that are never sorted on.
+func CheckSort(mapper *mapping.IndexMappingImpl, specs []models.SortSpec, config *models.IndexMapConfig) error {
+	if mapper == nil {
+		return nil
+	}
+	for _, spec := range specs {
+		for _, searchable := range config.Searchable {
+			if searchable.Field != spec.Field || !searchable.IsText() {
+				continue
+			}
+			fields := fieldMappingsAt(mapper.DefaultMapping, searchable.Field)
+			if findFieldMapping(fields, SortField(leafName(searchable.Field))) == nil {
+				return fmt.Errorf("mapping does not define %s, sorting on %s needs it", SortField(searchable.Field), searchable.Field)
+			}
+		}
+	}
+	return nil
+}
+
+// fieldMappingsAt follows a dotted path through the sub-documents, nil when the path is not mapped.
+func fieldMappingsAt(docMap *mapping.DocumentMapping, path string) []*mapping.FieldMapping {
+	elements := strings.Split(path, ".")
+	current := docMap
+	for _, element := range elements {
+		if current == nil {
+			return nil
+		}
+		current = current.Properties[element]
+	}
+	if current == nil {
+		return nil
+	}
+	return current.Fields
+}
+
+func findFieldMapping(fields []*mapping.FieldMapping, name string) *mapping.FieldMapping {
+	for _, field := range fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
 func NewIndexMappingFromBytes(indexMappingBytes []byte) (*mapping.IndexMappingImpl, error) {
 	indexMapping := mapping.NewIndexMapping()
 
@@ -0,0 +1,90 @@
+package mapping
+
+import (
+	"Scout.go/models"
+	"encoding/json"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"testing"
+)
+
+func TestCheckMapping(t *testing.T) {
+	config := func(highlight, autocomplete bool) *models.IndexMapConfig {
+		return &models.IndexMapConfig{Index: "products", UniqueId: "id", Highlight: highlight, Searchable: []models.IndexSearchable{
+			{Field: "attributes.title", Type: models.String, Autocomplete: autocomplete}, {Field: "price", Type: models.Number}}}
+	}
+	generated := func(c *models.IndexMapConfig) string {
+		mapper, err := NewIndexMapping(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := json.Marshal(mapper)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(raw)
+	}
+	const textOnly = `{"default_mapping":{"enabled":true,"dynamic":true,"properties":{"attributes":{"enabled":true,"dynamic":true,
+		"properties":{"title":{"enabled":true,"dynamic":true,"fields":[{"type":"text","index":true,"store":true}]}}}}}}`
+	const withSort = `{"default_mapping":{"enabled":true,"dynamic":true,"properties":{"attributes":{"enabled":true,"dynamic":true,
+		"properties":{"title":{"enabled":true,"dynamic":true,"fields":[{"type":"text","index":true,"store":true},
+		{"name":"title.sort","type":"text","analyzer":"keyword","index":true,"docvalues":true}]}}}}}}`
+	tests := []struct {
+		name    string
+		raw     string
+		config  *models.IndexMapConfig
+		wantErr bool
+	}{
+		{"generated", generated(config(false, false)), config(false, false), false},
+		{"generated with every feature", generated(config(true, true)), config(true, true), false},
+		{"field not mapped", `{"default_mapping":{"enabled":true,"dynamic":true}}`, config(false, false), true},
+		{"without sort field", textOnly, config(false, false), false},
+		{"unnamed text field", withSort, config(false, false), false},
+		{"missing autocomplete field", withSort, config(false, true), true},
+		{"missing term vectors", withSort, config(true, false), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapper, err := NewIndexMappingFromBytes([]byte(tt.raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := CheckMapping(mapper, tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("CheckMapping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckSort(t *testing.T) {
+	config := &models.IndexMapConfig{Index: "products", UniqueId: "id", Searchable: []models.IndexSearchable{
+		{Field: "attributes.title", Type: models.String}, {Field: "price", Type: models.Number}}}
+	const textOnly = `{"default_mapping":{"enabled":true,"dynamic":true,"properties":{"attributes":{"enabled":true,"dynamic":true,
+		"properties":{"title":{"enabled":true,"dynamic":true,"fields":[{"type":"text","index":true,"store":true}]}}},
+		"price":{"enabled":true,"dynamic":true,"fields":[{"type":"number","index":true,"store":true}]}}}}`
+	raw, err := NewIndexMappingFromBytes([]byte(textOnly))
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := NewIndexMapping(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		mapper  *mapping.IndexMappingImpl
+		sort    []models.SortSpec
+		wantErr bool
+	}{
+		{"generated", generated, []models.SortSpec{{Field: "attributes.title"}}, false},
+		{"raw without sort field", raw, []models.SortSpec{{Field: "attributes.title"}}, true},
+		{"raw on a number", raw, []models.SortSpec{{Field: "price"}, {Field: "_score"}}, false},
+		{"raw unsorted", raw, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckSort(tt.mapper, tt.sort, config); (err != nil) != tt.wantErr {
+				t.Errorf("CheckSort() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+import "errors"
+
+// AliasConfig points an alias at concrete indexes, writes through the alias go to the first one.
+type AliasConfig struct {
+	Indexes []string `json:"indexes"`
+}
+
+func (a *AliasConfig) Validate() error {
+	if len(a.Indexes) == 0 {
+		return errors.New("alias requires at least one index")
+	}
+	seen := make(map[string]bool, len(a.Indexes))
+	for _, index := range a.Indexes {
+		if index == "" {
+			return errors.New("alias index must not be empty")
+		}
+		if seen[index] {
+			return errors.New("alias index must not repeat")
+		}
+		seen[index] = true
+	}
+	return nil
+}
+
+// AliasSwap replaces one index of an alias with another in a single step.
+type AliasSwap struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (a *AliasSwap) Validate() error {
+	if a.From == "" || a.To == "" {
+		return errors.New("swap requires from and to")
+	}
+	if a.From == a.To {
+		return errors.New("swap requires different indexes")
+	}
+	return nil
+}
+
+type IndexAlias struct {
+	Alias     string   `json:"alias"`
+	Indexes   []string `json:"indexes"`
+	Execution string   `json:"execution,omitempty"`
+}
+
+type IndexAliases struct {
+	Aliases   map[string][]string `json:"aliases"`
+	Execution string              `json:"execution"`
+}
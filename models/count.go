@@ -2,6 +2,11 @@ package models
 
 type IndexRecordCount struct {
 	Index     string `json:"index"`
-	Count     uint32 `json:"count"`
+	Count     uint64 `json:"count"`
 	Execution string `json:"execution"`
 }
+
+// CountRequest narrows a count down to the documents matching the query.
+type CountRequest struct {
+	Query *QueryClause `json:"query"`
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"github.com/goccy/go-json"
+	"time"
 )
 
 type MakerHeader struct {
@@ -37,3 +38,10 @@ func (a *DbConfig) SafePort() uint {
 		return a.Port
 	}
 }
+
+// BinlogPosition is the last binlog position the watchman of an index synced to.
+type BinlogPosition struct {
+	Name     string    `json:"name"`
+	Pos      uint32    `json:"pos"`
+	SyncedAt time.Time `json:"synced_at"`
+}
@@ -1,17 +1,25 @@
 package models
 
 type IndexDeletion struct {
+	Index     string `json:"index"`
 	Status    bool   `json:"status"`
 	Execution string `json:"execution"`
 }
 
+// IndexRemoved asks the binlog service to stop watching for a deleted index.
+type IndexRemoved struct {
+	Index string
+}
+
 type IndexDataDeletion struct {
+	Index     string `json:"index"`
 	Status    bool   `json:"status"`
 	Uid       string `json:"uid"`
 	Execution string `json:"execution"`
 }
 
 type IndexDataBatchDeletion struct {
+	Index     string   `json:"index"`
 	Uid       []string `json:"uid"`
 	Execution string   `json:"execution"`
 }
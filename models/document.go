@@ -0,0 +1,25 @@
+package models
+
+import "errors"
+
+type IndexDocument struct {
+	Index     string                 `json:"index"`
+	Uid       string                 `json:"uid"`
+	Version   uint64                 `json:"version"`
+	Document  map[string]interface{} `json:"document"`
+	Execution string                 `json:"execution"`
+}
+
+// DocumentUpdate carries the fields to merge into a stored document, a null field is removed. With
+// Version set the update only applies while the document is still at that version.
+type DocumentUpdate struct {
+	Doc     map[string]interface{} `json:"doc"`
+	Version *uint64                `json:"version,omitempty"`
+}
+
+func (a *DocumentUpdate) Validate() error {
+	if len(a.Doc) == 0 {
+		return errors.New("doc with the fields to update is required")
+	}
+	return nil
+}
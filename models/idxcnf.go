@@ -1,7 +1,11 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
+	"reflect"
+	"strings"
+	"time"
 )
 
 type FieldType string
@@ -11,29 +15,250 @@ const (
 	Number   FieldType = "number"
 	DateTime FieldType = "datetime"
 	Boolean  FieldType = "boolean"
+	GeoPoint FieldType = "geopoint"
+	// Keyword is indexed untokenized for exact matches and facets.
+	Keyword FieldType = "keyword"
+	IP      FieldType = "ip"
+	// TextArray and NumberArray hold several values, given as a JSON array or a comma separated string.
+	TextArray   FieldType = "text[]"
+	NumberArray FieldType = "number[]"
 )
 
 type IndexSearchable struct {
-	Field string    `json:"field"`
-	Type  FieldType `json:"type"`
+	Field        string    `json:"field"`
+	Type         FieldType `json:"type"`
+	Autocomplete bool      `json:"autocomplete"`
+	Analyzer     string    `json:"analyzer,omitempty"`
+	Boost        float64   `json:"boost,omitempty"`
+	// Lat and Lon name the source columns a geopoint is built from, without them the field holds "lat,lon".
+	Lat string `json:"lat,omitempty"`
+	Lon string `json:"lon,omitempty"`
 }
 
 func (a *IndexSearchable) Validate() error {
+	if a.Field == "" || strings.HasPrefix(a.Field, ".") || strings.HasSuffix(a.Field, ".") || strings.Contains(a.Field, "..") {
+		return errors.New("invalid field path")
+	}
 	switch a.Type {
-	case String, Number, DateTime, Boolean:
-		return nil
+	case String, Number, DateTime, Boolean, GeoPoint, Keyword, IP, TextArray, NumberArray:
 	default:
 		return errors.New("invalid field type")
 	}
+	if a.Autocomplete && !a.IsText() {
+		return errors.New("autocomplete is only supported on string fields")
+	}
+	if a.Analyzer != "" && !a.IsText() {
+		return errors.New("analyzer is only supported on string fields")
+	}
+	if a.Boost < 0 {
+		return errors.New("boost must not be negative")
+	}
+	if a.Boost != 0 && !a.IsText() {
+		return errors.New("boost is only supported on string fields")
+	}
+	if (a.Lat != "" || a.Lon != "") && a.Type != GeoPoint {
+		return errors.New("lat and lon are only supported on geopoint fields")
+	}
+	if (a.Lat == "") != (a.Lon == "") {
+		return errors.New("geopoint requires both lat and lon columns")
+	}
+	return nil
+}
+
+// IsText reports whether the field holds analyzed text, a single string or an array of them.
+func (a *IndexSearchable) IsText() bool {
+	return a.Type == String || a.Type == TextArray
+}
+
+// IsArray reports whether the field holds several values.
+func (a *IndexSearchable) IsArray() bool {
+	return a.Type == TextArray || a.Type == NumberArray
+}
+
+// CustomAnalyzer chains registered char filters, a tokenizer and token filters under a new analyzer name.
+type CustomAnalyzer struct {
+	Name         string   `json:"name"`
+	CharFilters  []string `json:"char_filters,omitempty"`
+	Tokenizer    string   `json:"tokenizer"`
+	TokenFilters []string `json:"token_filters,omitempty"`
+}
+
+func (a *CustomAnalyzer) Validate() error {
+	if a.Name == "" || a.Tokenizer == "" {
+		return errors.New("custom analyzer requires name and tokenizer")
+	}
+	return nil
+}
+
+// Dictionary replaces the default English stop list and shields protected words from stemming.
+type Dictionary struct {
+	StopWords      []string `json:"stop_words"`
+	ProtectedWords []string `json:"protected_words"`
+}
+
+func (a *Dictionary) Validate() error {
+	for _, words := range [][]string{a.StopWords, a.ProtectedWords} {
+		for _, word := range words {
+			if strings.TrimSpace(word) == "" {
+				return errors.New("dictionary words must not be empty")
+			}
+		}
+	}
+	return nil
+}
+
+type IndexDictionary struct {
+	Index      string      `json:"index"`
+	Dictionary *Dictionary `json:"dictionary"`
+	Execution  string      `json:"execution"`
+	Message    string      `json:"message"`
+}
+
+// ScoringFunction folds a field into the text score: decay on a datetime field, log or static on a number field.
+type ScoringFunction struct {
+	Type   string  `json:"type"`
+	Field  string  `json:"field"`
+	Weight float64 `json:"weight,omitempty"`
+	// Scale is the age, e.g. "720h", at which a decayed value has dropped to Decay.
+	Scale  string  `json:"scale,omitempty"`
+	Decay  float64 `json:"decay,omitempty"`
+	Origin string  `json:"origin,omitempty"`
+}
+
+func (a *ScoringFunction) Validate() error {
+	if a.Field == "" {
+		return errors.New("scoring function requires field")
+	}
+	if a.Weight < 0 {
+		return errors.New("scoring function weight must not be negative")
+	}
+	switch a.Type {
+	case "decay":
+		scale, err := time.ParseDuration(a.Scale)
+		if err != nil || scale <= 0 {
+			return errors.New("decay scoring function requires a positive scale such as 720h")
+		}
+		if a.Decay < 0 || a.Decay >= 1 {
+			return errors.New("decay must be between 0 and 1")
+		}
+	case "log", "static":
+	default:
+		return errors.New("scoring function type must be decay / log / static")
+	}
+	return nil
+}
+
+// Scoring re-ranks the top window of relevance ordered hits with the scoring functions.
+type Scoring struct {
+	Functions []ScoringFunction `json:"functions"`
+	Mode      string            `json:"mode,omitempty"`
+	Window    int               `json:"window,omitempty"`
+}
+
+func (a *Scoring) Validate() error {
+	switch a.Mode {
+	case "", "multiply", "sum":
+	default:
+		return errors.New("scoring mode must be multiply / sum")
+	}
+	if a.Window < 0 {
+		return errors.New("scoring window must not be negative")
+	}
+	for i := range a.Functions {
+		if err := a.Functions[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type TypoTolerance struct {
+	Enabled             bool     `json:"enabled"`
+	MinWordSizeOneTypo  int      `json:"min_word_size_one_typo"`
+	MinWordSizeTwoTypos int      `json:"min_word_size_two_typos"`
+	DisableOnFields     []string `json:"disable_on_fields"`
+}
+
+func (a *TypoTolerance) Validate() error {
+	if a.MinWordSizeOneTypo < 0 || a.MinWordSizeTwoTypos < 0 {
+		return errors.New("typo tolerance word sizes must not be negative")
+	}
+	if a.MinWordSizeTwoTypos != 0 && a.MinWordSizeTwoTypos < a.oneTypo() {
+		return errors.New("min_word_size_two_typos must not be smaller than min_word_size_one_typo")
+	}
+	return nil
+}
+
+// Fuzziness returns the edit distance allowed for a word of the given length.
+func (a *TypoTolerance) Fuzziness(word string) int {
+	if a == nil || !a.Enabled {
+		return 0
+	}
+	size := len([]rune(word))
+	if size >= a.twoTypos() {
+		return 2
+	}
+	if size >= a.oneTypo() {
+		return 1
+	}
+	return 0
+}
+
+func (a *TypoTolerance) IsDisabledOn(field string) bool {
+	for _, f := range a.DisableOnFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *TypoTolerance) oneTypo() int {
+	if a.MinWordSizeOneTypo == 0 {
+		return 5
+	}
+	return a.MinWordSizeOneTypo
+}
+
+func (a *TypoTolerance) twoTypos() int {
+	if a.MinWordSizeTwoTypos == 0 {
+		return 9
+	}
+	return a.MinWordSizeTwoTypos
 }
 
 type IndexMapConfig struct {
-	Index      string            `json:"index"`
-	Searchable []IndexSearchable `json:"searchable"`
-	UniqueId   string            `json:"unique_id"`
+	Index         string            `json:"index"`
+	Searchable    []IndexSearchable `json:"searchable"`
+	UniqueId      string            `json:"unique_id"`
+	Highlight     bool              `json:"highlight"`
+	TypoTolerance *TypoTolerance    `json:"typo_tolerance,omitempty"`
+	Analyzers     []CustomAnalyzer  `json:"analyzers,omitempty"`
+	Dictionary    *Dictionary       `json:"dictionary,omitempty"`
+	Scoring       *Scoring          `json:"scoring,omitempty"`
 }
 
 func (x *IndexMapConfig) Validate() error {
+	for i := range x.Analyzers {
+		if err := x.Analyzers[i].Validate(); err != nil {
+			return err
+		}
+	}
+	if x.TypoTolerance != nil {
+		if err := x.TypoTolerance.Validate(); err != nil {
+			return err
+		}
+	}
+	if x.Dictionary != nil {
+		if err := x.Dictionary.Validate(); err != nil {
+			return err
+		}
+	}
+	if x.Scoring != nil {
+		if err := x.Scoring.Validate(); err != nil {
+			return err
+		}
+	}
 	switch x.UniqueId {
 	case "":
 		return errors.New("invalid unique field")
@@ -53,16 +278,32 @@ func (a *IndexMapConfig) IsDifferent(other *IndexMapConfig) bool {
 	if a.Index != other.Index {
 		return true
 	}
-	if len(a.Searchable) == 0 {
+	if len(a.Searchable) == 0 || len(a.Searchable) != len(other.Searchable) {
+		return true
+	}
+	if !reflect.DeepEqual(a.Analyzers, other.Analyzers) || !reflect.DeepEqual(a.Dictionary, other.Dictionary) {
 		return true
 	}
 	i := 0
 	for _, searchable := range a.Searchable {
 		for _, otherSearchable := range other.Searchable {
-			if searchable.Field == otherSearchable.Field && searchable.Type == otherSearchable.Type {
+			if searchable.mapped() == otherSearchable.mapped() {
 				i = i + 1
 			}
 		}
 	}
 	return i != len(other.Searchable)
 }
+
+// mapped leaves out what is only read per query, boosts weigh fields at search time and need no reindex.
+func (a IndexSearchable) mapped() IndexSearchable {
+	a.Boost = 0
+	return a
+}
+
+type IndexMappingResponse struct {
+	Index     string          `json:"index"`
+	Status    bool            `json:"status"`
+	Mapping   json.RawMessage `json:"mapping,omitempty"`
+	Execution string          `json:"execution"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "testing"
+
+func TestTypoToleranceFuzziness(t *testing.T) {
+	tests := []struct {
+		name string
+		typo *TypoTolerance
+		word string
+		want int
+	}{
+		{"nil", nil, "keyboard", 0},
+		{"disabled", &TypoTolerance{}, "keyboard", 0},
+		{"short word", &TypoTolerance{Enabled: true}, "shoe", 0},
+		{"one typo", &TypoTolerance{Enabled: true}, "shoes", 1},
+		{"two typos", &TypoTolerance{Enabled: true}, "keyboards", 2},
+		{"counts runes not bytes", &TypoTolerance{Enabled: true}, "café", 0},
+		{"custom sizes", &TypoTolerance{Enabled: true, MinWordSizeOneTypo: 3, MinWordSizeTwoTypos: 4}, "shoe", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.typo.Fuzziness(tt.word); got != tt.want {
+				t.Errorf("Fuzziness(%q) = %d, want %d", tt.word, got, tt.want)
+			}
+		})
+	}
+	reversed := &TypoTolerance{MinWordSizeOneTypo: 6, MinWordSizeTwoTypos: 4}
+	if err := reversed.Validate(); err == nil {
+		t.Error("two typo word size below the one typo size was accepted")
+	}
+}
+
+func TestIsDifferentIgnoresBoosts(t *testing.T) {
+	config := func(boost float64, analyzer string) *IndexMapConfig {
+		return &IndexMapConfig{Index: "products", UniqueId: "id", Searchable: []IndexSearchable{
+			{Field: "title", Type: String, Boost: boost, Analyzer: analyzer}, {Field: "price", Type: Number}}}
+	}
+	if config(2, "").IsDifferent(config(5, "")) {
+		t.Error("a boost change asks for a reindex, boosts are only read at query time")
+	}
+	if !config(2, "").IsDifferent(config(2, "keyword")) {
+		t.Error("an analyzer change does not ask for a reindex")
+	}
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type IndexNames struct {
 	Indexes   []string `json:"indexes"`
 	Execution string   `json:"execution"`
@@ -9,3 +11,14 @@ type IndexStats struct {
 	Stats     map[string]map[string]interface{} `json:"stats"`
 	Execution string                            `json:"execution"`
 }
+
+type IndexStatistics struct {
+	Index       string          `json:"index"`
+	DocCount    uint64          `json:"doc_count"`
+	DiskSize    uint64          `json:"disk_size"`
+	Segments    uint64          `json:"segments"`
+	LastIndexed *time.Time      `json:"last_indexed,omitempty"`
+	Binlog      *BinlogPosition `json:"binlog,omitempty"`
+	Reindex     string          `json:"reindex"`
+	Execution   string          `json:"execution"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+const (
+	ReindexIdle      = "idle"
+	ReindexRunning   = "running"
+	ReindexCompleted = "completed"
+	ReindexFailed    = "failed"
+)
+
+// ReindexStatus reports the progress of copying an index into one built with its new mapping.
+type ReindexStatus struct {
+	Index      string     `json:"index"`
+	State      string     `json:"state"`
+	Copied     uint64     `json:"copied"`
+	Total      uint64     `json:"total"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Execution  string     `json:"execution,omitempty"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+type PinnedDoc struct {
+	Id       string `json:"id"`
+	Position int    `json:"position"`
+}
+
+type BoostedDoc struct {
+	Id    string  `json:"id"`
+	Boost float64 `json:"boost"`
+}
+
+// MerchandisingRule pins, hides or boosts documents for queries matching the pattern between Start and End.
+type MerchandisingRule struct {
+	Id      string       `json:"id"`
+	Pattern string       `json:"pattern"`
+	Match   string       `json:"match,omitempty"`
+	Pins    []PinnedDoc  `json:"pins,omitempty"`
+	Hide    []string     `json:"hide,omitempty"`
+	Boosts  []BoostedDoc `json:"boosts,omitempty"`
+	Start   string       `json:"start,omitempty"`
+	End     string       `json:"end,omitempty"`
+}
+
+func (a *MerchandisingRule) Validate() error {
+	if a.Id == "" || a.Pattern == "" {
+		return errors.New("rule requires id and pattern")
+	}
+	switch a.Match {
+	case "", "exact", "prefix", "contains":
+	default:
+		return fmt.Errorf("rule %s match must be exact / prefix / contains", a.Id)
+	}
+	if len(a.Pins)+len(a.Hide)+len(a.Boosts) == 0 {
+		return fmt.Errorf("rule %s requires pins, hide or boosts", a.Id)
+	}
+	for _, pin := range a.Pins {
+		if pin.Id == "" || pin.Position < 1 {
+			return fmt.Errorf("rule %s pins require id and a position from 1", a.Id)
+		}
+	}
+	for _, boost := range a.Boosts {
+		if boost.Id == "" || boost.Boost <= 0 {
+			return fmt.Errorf("rule %s boosts require id and a positive boost", a.Id)
+		}
+	}
+	return nil
+}
+
+type RuleSet struct {
+	Rules []MerchandisingRule `json:"rules"`
+}
+
+func (a *RuleSet) Validate() error {
+	ids := make(map[string]bool, len(a.Rules))
+	for i := range a.Rules {
+		if err := a.Rules[i].Validate(); err != nil {
+			return err
+		}
+		if ids[a.Rules[i].Id] {
+			return fmt.Errorf("duplicate rule id %s", a.Rules[i].Id)
+		}
+		ids[a.Rules[i].Id] = true
+	}
+	return nil
+}
+
+type IndexRules struct {
+	Index     string              `json:"index"`
+	Rules     []MerchandisingRule `json:"rules"`
+	Execution string              `json:"execution"`
+}
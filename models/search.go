@@ -0,0 +1,401 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+type TermClause struct {
+	Field string   `json:"field"`
+	Value string   `json:"value"`
+	Boost *float64 `json:"boost,omitempty"`
+}
+
+type MatchClause struct {
+	Field     string   `json:"field"`
+	Query     string   `json:"query"`
+	Operator  string   `json:"operator,omitempty"`
+	Fuzziness int      `json:"fuzziness,omitempty"`
+	Boost     *float64 `json:"boost,omitempty"`
+}
+
+// MultiMatchClause spreads the query across fields, a field may carry a weight as "title^3".
+type MultiMatchClause struct {
+	Query     string   `json:"query"`
+	Fields    []string `json:"fields,omitempty"`
+	Operator  string   `json:"operator,omitempty"`
+	Fuzziness int      `json:"fuzziness,omitempty"`
+	Boost     *float64 `json:"boost,omitempty"`
+}
+
+type RangeClause struct {
+	Field string   `json:"field"`
+	Gt    *float64 `json:"gt,omitempty"`
+	Gte   *float64 `json:"gte,omitempty"`
+	Lt    *float64 `json:"lt,omitempty"`
+	Lte   *float64 `json:"lte,omitempty"`
+	Boost *float64 `json:"boost,omitempty"`
+}
+
+type DateRangeClause struct {
+	Field string   `json:"field"`
+	Gt    string   `json:"gt,omitempty"`
+	Gte   string   `json:"gte,omitempty"`
+	Lt    string   `json:"lt,omitempty"`
+	Lte   string   `json:"lte,omitempty"`
+	Boost *float64 `json:"boost,omitempty"`
+}
+
+type LatLon struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+func (a LatLon) Validate() error {
+	if a.Lat < -90 || a.Lat > 90 || a.Lon < -180 || a.Lon > 180 {
+		return errors.New("lat must be within -90..90 and lon within -180..180")
+	}
+	return nil
+}
+
+// GeoDistanceClause matches points within Distance, e.g. "5km", of the origin.
+type GeoDistanceClause struct {
+	Field    string   `json:"field"`
+	Origin   LatLon   `json:"origin"`
+	Distance string   `json:"distance"`
+	Boost    *float64 `json:"boost,omitempty"`
+}
+
+type GeoBBoxClause struct {
+	Field       string   `json:"field"`
+	TopLeft     LatLon   `json:"top_left"`
+	BottomRight LatLon   `json:"bottom_right"`
+	Boost       *float64 `json:"boost,omitempty"`
+}
+
+type GeoPolygonClause struct {
+	Field  string   `json:"field"`
+	Points []LatLon `json:"points"`
+	Boost  *float64 `json:"boost,omitempty"`
+}
+
+// IPRangeClause matches IP fields within the CIDR block, e.g. "10.0.0.0/8".
+type IPRangeClause struct {
+	Field string   `json:"field"`
+	CIDR  string   `json:"cidr"`
+	Boost *float64 `json:"boost,omitempty"`
+}
+
+type BoolClause struct {
+	Must      []QueryClause `json:"must,omitempty"`
+	Should    []QueryClause `json:"should,omitempty"`
+	MustNot   []QueryClause `json:"must_not,omitempty"`
+	MinShould int           `json:"minimum_should_match,omitempty"`
+	Boost     *float64      `json:"boost,omitempty"`
+}
+
+// QueryClause is a single node of the search DSL, exactly one member must be set.
+type QueryClause struct {
+	MatchAll    *struct{}          `json:"match_all,omitempty"`
+	QueryString string             `json:"query_string,omitempty"`
+	Bool        *BoolClause        `json:"bool,omitempty"`
+	Term        *TermClause        `json:"term,omitempty"`
+	Match       *MatchClause       `json:"match,omitempty"`
+	Phrase      *MatchClause       `json:"phrase,omitempty"`
+	MultiMatch  *MultiMatchClause  `json:"multi_match,omitempty"`
+	Prefix      *TermClause        `json:"prefix,omitempty"`
+	Wildcard    *TermClause        `json:"wildcard,omitempty"`
+	Regexp      *TermClause        `json:"regexp,omitempty"`
+	Range       *RangeClause       `json:"range,omitempty"`
+	DateRange   *DateRangeClause   `json:"date_range,omitempty"`
+	GeoDistance *GeoDistanceClause `json:"geo_distance,omitempty"`
+	GeoBBox     *GeoBBoxClause     `json:"geo_bbox,omitempty"`
+	GeoPolygon  *GeoPolygonClause  `json:"geo_polygon,omitempty"`
+	IPRange     *IPRangeClause     `json:"ip_range,omitempty"`
+}
+
+func (a *QueryClause) Validate() error {
+	set := 0
+	if a.MatchAll != nil {
+		set++
+	}
+	if a.QueryString != "" {
+		set++
+	}
+	if a.Bool != nil {
+		set++
+		if len(a.Bool.Must)+len(a.Bool.Should)+len(a.Bool.MustNot) == 0 {
+			return errors.New("bool clause requires must, should or must_not")
+		}
+		for _, clauses := range [][]QueryClause{a.Bool.Must, a.Bool.Should, a.Bool.MustNot} {
+			for i := range clauses {
+				if err := clauses[i].Validate(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for name, term := range map[string]*TermClause{"term": a.Term, "prefix": a.Prefix, "wildcard": a.Wildcard, "regexp": a.Regexp} {
+		if term != nil {
+			set++
+			if term.Value == "" {
+				return fmt.Errorf("%s clause requires value", name)
+			}
+		}
+	}
+	for name, match := range map[string]*MatchClause{"match": a.Match, "phrase": a.Phrase} {
+		if match != nil {
+			set++
+			if match.Query == "" {
+				return fmt.Errorf("%s clause requires query", name)
+			}
+			if match.Operator != "" && match.Operator != "or" && match.Operator != "and" {
+				return fmt.Errorf("%s clause operator must be or / and", name)
+			}
+		}
+	}
+	if a.MultiMatch != nil {
+		set++
+		if a.MultiMatch.Query == "" {
+			return errors.New("multi_match clause requires query")
+		}
+		if a.MultiMatch.Operator != "" && a.MultiMatch.Operator != "or" && a.MultiMatch.Operator != "and" {
+			return errors.New("multi_match clause operator must be or / and")
+		}
+	}
+	if a.Range != nil {
+		set++
+		if a.Range.Field == "" {
+			return errors.New("range clause requires field")
+		}
+		if (a.Range.Gt != nil && a.Range.Gte != nil) || (a.Range.Lt != nil && a.Range.Lte != nil) {
+			return errors.New("range clause accepts only one lower and one upper bound")
+		}
+		if a.Range.Gt == nil && a.Range.Gte == nil && a.Range.Lt == nil && a.Range.Lte == nil {
+			return errors.New("range clause requires a lower or an upper bound")
+		}
+	}
+	if a.DateRange != nil {
+		set++
+		if a.DateRange.Field == "" {
+			return errors.New("date_range clause requires field")
+		}
+		if (a.DateRange.Gt != "" && a.DateRange.Gte != "") || (a.DateRange.Lt != "" && a.DateRange.Lte != "") {
+			return errors.New("date_range clause accepts only one lower and one upper bound")
+		}
+		if a.DateRange.Gt == "" && a.DateRange.Gte == "" && a.DateRange.Lt == "" && a.DateRange.Lte == "" {
+			return errors.New("date_range clause requires a lower or an upper bound")
+		}
+	}
+	if a.GeoDistance != nil {
+		set++
+		if a.GeoDistance.Field == "" || a.GeoDistance.Distance == "" {
+			return errors.New("geo_distance clause requires field and distance")
+		}
+		if err := a.GeoDistance.Origin.Validate(); err != nil {
+			return err
+		}
+	}
+	if a.GeoBBox != nil {
+		set++
+		if a.GeoBBox.Field == "" {
+			return errors.New("geo_bbox clause requires field")
+		}
+		for _, point := range []LatLon{a.GeoBBox.TopLeft, a.GeoBBox.BottomRight} {
+			if err := point.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	if a.GeoPolygon != nil {
+		set++
+		if a.GeoPolygon.Field == "" || len(a.GeoPolygon.Points) < 3 {
+			return errors.New("geo_polygon clause requires field and at least three points")
+		}
+		for _, point := range a.GeoPolygon.Points {
+			if err := point.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	if a.IPRange != nil {
+		set++
+		if a.IPRange.Field == "" {
+			return errors.New("ip_range clause requires field")
+		}
+		if _, _, err := net.ParseCIDR(a.IPRange.CIDR); err != nil && net.ParseIP(a.IPRange.CIDR) == nil {
+			return errors.New("ip_range clause requires a CIDR block or an IP address")
+		}
+	}
+	if set != 1 {
+		return errors.New("query clause must contain exactly one query type")
+	}
+	return nil
+}
+
+type NumericRangeBucket struct {
+	Name string   `json:"name"`
+	Min  *float64 `json:"min,omitempty"`
+	Max  *float64 `json:"max,omitempty"`
+}
+
+type DateRangeBucket struct {
+	Name  string `json:"name"`
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
+
+type DateHistogram struct {
+	Interval string `json:"interval"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+}
+
+// FacetRequest is a term facet unless numeric ranges, date ranges or a date histogram is given.
+type FacetRequest struct {
+	Field         string               `json:"field"`
+	Size          int                  `json:"size"`
+	NumericRanges []NumericRangeBucket `json:"numeric_ranges,omitempty"`
+	DateRanges    []DateRangeBucket    `json:"date_ranges,omitempty"`
+	DateHistogram *DateHistogram       `json:"date_histogram,omitempty"`
+}
+
+func (a *FacetRequest) Validate() error {
+	if a.Field == "" {
+		return errors.New("facet requires field")
+	}
+	if a.Size < 0 {
+		return errors.New("facet size must not be negative")
+	}
+	kinds := 0
+	if len(a.NumericRanges) > 0 {
+		kinds++
+		for _, r := range a.NumericRanges {
+			if r.Min == nil && r.Max == nil {
+				return fmt.Errorf("numeric range %s requires min or max", r.Name)
+			}
+		}
+	}
+	if len(a.DateRanges) > 0 {
+		kinds++
+		for _, r := range a.DateRanges {
+			if r.Start == "" && r.End == "" {
+				return fmt.Errorf("date range %s requires start or end", r.Name)
+			}
+		}
+	}
+	if a.DateHistogram != nil {
+		kinds++
+		switch a.DateHistogram.Interval {
+		case "hour", "day", "week", "month", "year":
+		default:
+			return errors.New("date histogram interval must be hour / day / week / month / year")
+		}
+		if a.DateHistogram.Start == "" || a.DateHistogram.End == "" {
+			return errors.New("date histogram requires start and end")
+		}
+	}
+	if kinds > 1 {
+		return errors.New("facet accepts only one of numeric_ranges, date_ranges or date_histogram")
+	}
+	return nil
+}
+
+type HighlightRequest struct {
+	Style        string   `json:"style"`
+	Fields       []string `json:"fields,omitempty"`
+	PreTag       string   `json:"pre_tag,omitempty"`
+	PostTag      string   `json:"post_tag,omitempty"`
+	Fragments    int      `json:"fragments,omitempty"`
+	FragmentSize int      `json:"fragment_size,omitempty"`
+}
+
+func (a *HighlightRequest) Validate() error {
+	switch a.Style {
+	case "", "html", "ansi":
+	default:
+		return errors.New("highlight style must be html / ansi")
+	}
+	if a.Fragments < 0 || a.FragmentSize < 0 {
+		return errors.New("highlight fragments and fragment_size must not be negative")
+	}
+	return nil
+}
+
+// SortSpec orders by a field, a geopoint field is ordered by its distance from Origin in Unit, meters by default.
+type SortSpec struct {
+	Field   string  `json:"field"`
+	Order   string  `json:"order,omitempty"`
+	Missing string  `json:"missing,omitempty"`
+	Origin  *LatLon `json:"origin,omitempty"`
+	Unit    string  `json:"unit,omitempty"`
+}
+
+func (a *SortSpec) Validate() error {
+	if a.Field == "" {
+		return errors.New("sort requires field")
+	}
+	switch a.Order {
+	case "", "asc", "desc":
+	default:
+		return errors.New("sort order must be asc / desc")
+	}
+	switch a.Missing {
+	case "", "first", "last":
+	default:
+		return errors.New("sort missing must be first / last")
+	}
+	if a.Origin != nil {
+		return a.Origin.Validate()
+	}
+	return nil
+}
+
+type SearchRequest struct {
+	Query     *QueryClause            `json:"query"`
+	Offset    int                     `json:"offset"`
+	Limit     int                     `json:"limit"`
+	Facets    map[string]FacetRequest `json:"facets,omitempty"`
+	Highlight *HighlightRequest       `json:"highlight,omitempty"`
+	Sort      []SortSpec              `json:"sort,omitempty"`
+	Cursor    string                  `json:"cursor,omitempty"`
+	// Scoring overrides the scoring functions of the index, an empty function list disables them.
+	Scoring *Scoring `json:"scoring,omitempty"`
+}
+
+func (a *SearchRequest) Validate() error {
+	if a.Offset < 0 || a.Limit < 0 {
+		return errors.New("offset and limit must not be negative")
+	}
+	if a.Cursor != "" && a.Offset != 0 {
+		return errors.New("offset must be 0 when paging with a cursor")
+	}
+	if a.Highlight != nil {
+		if err := a.Highlight.Validate(); err != nil {
+			return err
+		}
+	}
+	if a.Scoring != nil {
+		if a.Cursor != "" || len(a.Sort) > 0 {
+			return errors.New("scoring can not be combined with a cursor or sort")
+		}
+		if err := a.Scoring.Validate(); err != nil {
+			return err
+		}
+	}
+	for i := range a.Sort {
+		if err := a.Sort[i].Validate(); err != nil {
+			return err
+		}
+	}
+	for name, facet := range a.Facets {
+		if err := facet.Validate(); err != nil {
+			return fmt.Errorf("facet %s: %v", name, err)
+		}
+	}
+	if a.Query != nil {
+		return a.Query.Validate()
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// validate decodes a search request body and validates it.
+func validate(t *testing.T, body string) error {
+	t.Helper()
+	var request SearchRequest
+	if err := json.Unmarshal([]byte(body), &request); err != nil {
+		t.Fatalf("unmarshal %s: %v", body, err)
+	}
+	return request.Validate()
+}
+
+func TestQueryClauseValidate(t *testing.T) {
+	valid := []string{
+		`{}`,
+		`{"query":{"match_all":{}}}`,
+		`{"query":{"match":{"field":"title","query":"shoe","operator":"and"}}}`,
+		`{"query":{"bool":{"must":[{"term":{"field":"sku","value":"p1"}}],"must_not":[{"prefix":{"field":"sku","value":"x"}}]}}}`,
+		`{"query":{"range":{"field":"price","gt":1,"lte":2}}}`,
+	}
+	for _, body := range valid {
+		if err := validate(t, body); err != nil {
+			t.Errorf("%s: unexpected error %v", body, err)
+		}
+	}
+	invalid := map[string]string{
+		"two query types":           `{"query":{"match_all":{},"query_string":"shoe"}}`,
+		"no query type":             `{"query":{}}`,
+		"empty bool":                `{"query":{"bool":{}}}`,
+		"invalid nested clause":     `{"query":{"bool":{"should":[{"term":{"field":"sku"}}]}}}`,
+		"unknown operator":          `{"query":{"match":{"field":"title","query":"shoe","operator":"xor"}}}`,
+		"phrase without query":      `{"query":{"phrase":{"field":"title"}}}`,
+		"two lower bounds":          `{"query":{"range":{"field":"price","gt":1,"gte":2}}}`,
+		"two upper date bounds":     `{"query":{"date_range":{"field":"created","lt":"2024-01-01","lte":"2024-01-02"}}}`,
+		"range without bounds":      `{"query":{"range":{"field":"price"}}}`,
+		"date range without bounds": `{"query":{"date_range":{"field":"created"}}}`,
+		"negative offset":           `{"offset":-1}`,
+	}
+	for name, body := range invalid {
+		if err := validate(t, body); err == nil {
+			t.Errorf("%s: %s was accepted", name, body)
+		}
+	}
+}
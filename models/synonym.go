@@ -0,0 +1,30 @@
+package models
+
+import "errors"
+
+type SynonymSet struct {
+	Groups [][]string `json:"groups"`
+}
+
+func (a *SynonymSet) Validate() error {
+	if len(a.Groups) == 0 {
+		return errors.New("at least one synonym group is required")
+	}
+	for _, group := range a.Groups {
+		if len(group) < 2 {
+			return errors.New("synonym group requires at least two terms")
+		}
+		for _, term := range group {
+			if term == "" {
+				return errors.New("synonym term must not be empty")
+			}
+		}
+	}
+	return nil
+}
+
+type IndexSynonyms struct {
+	Index     string     `json:"index"`
+	Groups    [][]string `json:"groups"`
+	Execution string     `json:"execution"`
+}
@@ -0,0 +1,156 @@
+package reg
+
+import (
+	yrr "Scout.go/errors"
+	"Scout.go/storage"
+	"sync"
+)
+
+var (
+	aliases   = make(map[string][]string)
+	aliasesMu sync.RWMutex
+)
+
+// SetAlias points the alias at the given indexes, replacing whatever it pointed at before.
+func SetAlias(name string, indexes []string) error {
+	if _, err := IndexByName(name); err == nil {
+		return yrr.ErrAliasIsIndex
+	}
+	for _, index := range indexes {
+		if _, err := IndexByName(index); err != nil {
+			return err
+		}
+	}
+	aliasesMu.Lock()
+	defer aliasesMu.Unlock()
+
+	aliases[name] = append([]string(nil), indexes...)
+	return nil
+}
+
+// SwapAlias replaces one index of the alias with another, readers see either the old or the new set.
+func SwapAlias(name, from, to string) ([]string, error) {
+	if _, err := IndexByName(to); err != nil {
+		return nil, err
+	}
+	aliasesMu.Lock()
+	defer aliasesMu.Unlock()
+
+	indexes, exists := aliases[name]
+	if !exists {
+		return nil, yrr.ErrAliasNotFound
+	}
+	swapped := make([]string, 0, len(indexes))
+	found := false
+	for _, index := range indexes {
+		switch index {
+		case from:
+			found = true
+			swapped = append(swapped, to)
+		case to:
+			// already a member, the swap leaves it in the place of from
+		default:
+			swapped = append(swapped, index)
+		}
+	}
+	if !found {
+		return nil, yrr.ErrAliasMember
+	}
+	aliases[name] = swapped
+	return swapped, nil
+}
+
+// DropAliasMember takes a deleted index out of every alias, an alias left without indexes is removed.
+// It returns the aliases that changed with their remaining indexes.
+func DropAliasMember(index string) map[string][]string {
+	aliasesMu.Lock()
+	defer aliasesMu.Unlock()
+
+	changed := make(map[string][]string)
+	for name, indexes := range aliases {
+		kept := make([]string, 0, len(indexes))
+		for _, member := range indexes {
+			if member != index {
+				kept = append(kept, member)
+			}
+		}
+		if len(kept) == len(indexes) {
+			continue
+		}
+		if len(kept) == 0 {
+			delete(aliases, name)
+		} else {
+			aliases[name] = kept
+		}
+		changed[name] = kept
+	}
+	return changed
+}
+
+func RemoveAlias(name string) error {
+	aliasesMu.Lock()
+	defer aliasesMu.Unlock()
+
+	if _, exists := aliases[name]; !exists {
+		return yrr.ErrAliasNotFound
+	}
+	delete(aliases, name)
+	return nil
+}
+
+func AliasByName(name string) ([]string, error) {
+	aliasesMu.RLock()
+	defer aliasesMu.RUnlock()
+
+	indexes, exists := aliases[name]
+	if !exists {
+		return nil, yrr.ErrAliasNotFound
+	}
+	return append([]string(nil), indexes...), nil
+}
+
+func Aliases() map[string][]string {
+	aliasesMu.RLock()
+	defer aliasesMu.RUnlock()
+
+	res := make(map[string][]string, len(aliases))
+	for name, indexes := range aliases {
+		res[name] = append([]string(nil), indexes...)
+	}
+	return res
+}
+
+// SearchIndex resolves an index or an alias for reading, an alias over several indexes searches them as one.
+func SearchIndex(name string) (*storage.Index, error) {
+	if index, err := IndexByName(name); err == nil {
+		return index, nil
+	}
+	indexes, err := AliasByName(name)
+	if err != nil {
+		return nil, yrr.ErrRegNotFound
+	}
+	members := make([]*storage.Index, 0, len(indexes))
+	for _, index := range indexes {
+		member, err := IndexByName(index)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	if len(members) == 1 {
+		return members[0], nil
+	}
+	return storage.NewIndexAlias(members), nil
+}
+
+// WriteIndex resolves an index or an alias for writing, an alias writes to its first index.
+func WriteIndex(name string) (*storage.Index, error) {
+	if index, err := IndexByName(name); err == nil {
+		return index, nil
+	}
+	indexes, err := AliasByName(name)
+	if err != nil {
+		return nil, yrr.ErrRegNotFound
+	}
+	return IndexByName(indexes[0])
+}
@@ -0,0 +1,160 @@
+package reg
+
+import (
+	yrr "Scout.go/errors"
+	"Scout.go/storage"
+	"reflect"
+	"testing"
+)
+
+// withIndexes registers placeholder indexes and empties the aliases, both are restored after the test.
+func withIndexes(t *testing.T, names ...string) map[string]*storage.Index {
+	t.Helper()
+	registryMu.Lock()
+	aliasesMu.Lock()
+	savedRegistry, savedAliases := Registry, aliases
+	Registry, aliases = make(IndexRegistry), make(map[string][]string)
+	aliasesMu.Unlock()
+	registryMu.Unlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		aliasesMu.Lock()
+		Registry, aliases = savedRegistry, savedAliases
+		aliasesMu.Unlock()
+		registryMu.Unlock()
+	})
+	indexes := make(map[string]*storage.Index, len(names))
+	for _, name := range names {
+		indexes[name] = &storage.Index{}
+		RegisterType(name, indexes[name])
+	}
+	return indexes
+}
+
+func TestResolveIndex(t *testing.T) {
+	tests := []struct {
+		name      string
+		lookup    string
+		wantRead  string
+		wantWrite string
+		wantView  bool
+		wantErr   error
+	}{
+		{name: "index", lookup: "products_v1", wantRead: "products_v1", wantWrite: "products_v1"},
+		{name: "alias of one index", lookup: "current", wantRead: "products_v2", wantWrite: "products_v2"},
+		{name: "alias of several indexes", lookup: "all", wantView: true, wantWrite: "products_v2"},
+		{name: "unknown", lookup: "nope", wantErr: yrr.ErrRegNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexes := withIndexes(t, "products_v1", "products_v2")
+			if err := SetAlias("current", []string{"products_v2"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := SetAlias("all", []string{"products_v2", "products_v1"}); err != nil {
+				t.Fatal(err)
+			}
+			read, err := SearchIndex(tt.lookup)
+			if err != tt.wantErr {
+				t.Fatalf("SearchIndex() error = %v, want %v", err, tt.wantErr)
+			}
+			write, err := WriteIndex(tt.lookup)
+			if err != tt.wantErr {
+				t.Fatalf("WriteIndex() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			switch {
+			case tt.wantView && (read == indexes["products_v1"] || read == indexes["products_v2"]):
+				t.Errorf("SearchIndex() returned a member, want a view over every member")
+			case !tt.wantView && read != indexes[tt.wantRead]:
+				t.Errorf("SearchIndex() did not resolve to %s", tt.wantRead)
+			}
+			if write != indexes[tt.wantWrite] {
+				t.Errorf("WriteIndex() did not resolve to %s", tt.wantWrite)
+			}
+		})
+	}
+}
+
+func TestSetAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		alias   string
+		indexes []string
+		wantErr error
+	}{
+		{"alias", "products", []string{"products_v1"}, nil},
+		{"alias named like an index", "products_v1", []string{"products_v2"}, yrr.ErrAliasIsIndex},
+		{"unknown member", "products", []string{"products_v3"}, yrr.ErrRegNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withIndexes(t, "products_v1", "products_v2")
+			if err := SetAlias(tt.alias, tt.indexes); err != tt.wantErr {
+				t.Fatalf("SetAlias() error = %v, want %v", err, tt.wantErr)
+			}
+			got, err := AliasByName(tt.alias)
+			if tt.wantErr != nil {
+				if err != yrr.ErrAliasNotFound {
+					t.Errorf("AliasByName() = %v, %v, want the alias not to exist", got, err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.indexes) {
+				t.Errorf("AliasByName() = %v, want %v", got, tt.indexes)
+			}
+		})
+	}
+}
+
+func TestSwapAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		want    []string
+		wantErr error
+	}{
+		{"replace in place", "products_v1", "products_v3", []string{"products_v3", "products_v2"}, nil},
+		{"swap to a member", "products_v1", "products_v2", []string{"products_v2"}, nil},
+		{"from not a member", "products_v3", "products_v1", nil, yrr.ErrAliasMember},
+		{"to not an index", "products_v1", "products_v4", nil, yrr.ErrRegNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withIndexes(t, "products_v1", "products_v2", "products_v3")
+			if err := SetAlias("products", []string{"products_v1", "products_v2"}); err != nil {
+				t.Fatal(err)
+			}
+			got, err := SwapAlias("products", tt.from, tt.to)
+			if err != tt.wantErr {
+				t.Fatalf("SwapAlias() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SwapAlias() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDropAliasMember(t *testing.T) {
+	withIndexes(t, "products_v1", "products_v2")
+	for alias, indexes := range map[string][]string{"both": {"products_v1", "products_v2"}, "only": {"products_v1"}, "other": {"products_v2"}} {
+		if err := SetAlias(alias, indexes); err != nil {
+			t.Fatal(err)
+		}
+	}
+	changed := DropAliasMember("products_v1")
+	want := map[string][]string{"both": {"products_v2"}, "only": {}}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("DropAliasMember() = %v, want %v", changed, want)
+	}
+	if _, err := AliasByName("only"); err != yrr.ErrAliasNotFound {
+		t.Errorf("alias left without indexes still resolves, error = %v", err)
+	}
+	if got, _ := AliasByName("other"); !reflect.DeepEqual(got, []string{"products_v2"}) {
+		t.Errorf("unrelated alias = %v, want it unchanged", got)
+	}
+}
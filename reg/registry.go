@@ -5,23 +5,57 @@ import (
 	"Scout.go/storage"
 	"errors"
 	"fmt"
+	"sync"
 )
 
 type IndexRegistry map[string]*storage.Index
 
 var Registry = make(IndexRegistry, 0)
 
+// registryMu guards Registry, indexes come and go while requests resolve them
+var registryMu sync.RWMutex
+
 func RegisterType(name string, typ *storage.Index) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
 	if _, exists := Registry[name]; exists {
 		panic(errors.New(fmt.Sprintf("attempted to register duplicate index: %s", name)))
 	}
 	Registry[name] = typ
 }
 
+func UnregisterType(name string) (*storage.Index, error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	index, exists := Registry[name]
+	if !exists {
+		return nil, yrr.ErrRegNotFound
+	}
+	delete(Registry, name)
+	return index, nil
+}
+
 func IndexByName(name string) (*storage.Index, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
 	index, exists := Registry[name]
 	if exists {
 		return index, nil
 	}
 	return nil, yrr.ErrRegNotFound
 }
+
+// Indexes returns a snapshot of the registry that is safe to range over.
+func Indexes() map[string]*storage.Index {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	res := make(map[string]*storage.Index, len(Registry))
+	for name, index := range Registry {
+		res[name] = index
+	}
+	return res
+}
@@ -0,0 +1,66 @@
+package routes
+
+import (
+	"Scout.go/engine"
+	"Scout.go/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func PutAlias(c *gin.Context) {
+	var reqBody models.AliasConfig
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := reqBody.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := engine.PutAlias(c.Param("alias"), reqBody)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func GetAlias(c *gin.Context) {
+	resp, err := engine.GetAlias(c.Param("alias"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func GetAliases(c *gin.Context) {
+	c.JSON(http.StatusOK, engine.GetAliases())
+}
+
+func DeleteAlias(c *gin.Context) {
+	resp, err := engine.DeleteAlias(c.Param("alias"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func SwapAlias(c *gin.Context) {
+	var reqBody models.AliasSwap
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := reqBody.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := engine.SwapAlias(c.Param("alias"), reqBody)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
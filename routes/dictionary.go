@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"Scout.go/engine"
+	"Scout.go/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func PutDictionary(c *gin.Context) {
+	var reqBody models.Dictionary
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := reqBody.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := engine.PutDictionary(c.Param("index"), reqBody)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func GetDictionary(c *gin.Context) {
+	resp, err := engine.GetDictionary(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
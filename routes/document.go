@@ -0,0 +1,102 @@
+package routes
+
+import (
+	"Scout.go/engine"
+	yrr "Scout.go/errors"
+	"Scout.go/models"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetDocument(c *gin.Context) {
+	resp, err := engine.GetDocument(c.Param("index"), c.Param("id"))
+	if err != nil {
+		c.JSON(documentStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func PutDocument(c *gin.Context) {
+	var reqBody map[string]interface{}
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := engine.UpsertDocument(c.Param("index"), c.Param("id"), reqBody)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func PatchDocument(c *gin.Context) {
+	var reqBody models.DocumentUpdate
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := reqBody.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := engine.UpdateDocument(c.Param("index"), c.Param("id"), reqBody)
+	if err != nil {
+		c.JSON(documentStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func DeleteDocument(c *gin.Context) {
+	resp, err := engine.DeleteDocument(c.Param("index"), c.Param("id"))
+	if err != nil {
+		c.JSON(documentStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func PostBulk(c *gin.Context) {
+	var reqBody []map[string]interface{}
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := engine.UpsertDocuments(c.Param("index"), reqBody)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func PostBulkDelete(c *gin.Context) {
+	var reqBody models.IndexDataBatchDeletion
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(reqBody.Uid) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "uid is required"})
+		return
+	}
+	resp, err := engine.DeleteDocuments(c.Param("index"), reqBody.Uid)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func documentStatus(err error) int {
+	if errors.Is(err, yrr.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, yrr.ErrVersionConflict) {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
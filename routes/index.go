@@ -28,6 +28,15 @@ func PutConfig(c *gin.Context) {
 	}
 }
 
+func DeleteIndex(c *gin.Context) {
+	resp, err := engine.DeleteIndex(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 func GetIndexes(c *gin.Context) {
 	c.JSON(http.StatusOK, engine.Indexes())
 }
@@ -36,6 +45,48 @@ func GetIndexStats(c *gin.Context) {
 	c.JSON(http.StatusOK, engine.IndexStats())
 }
 
+func GetIndexStatistics(c *gin.Context) {
+	resp, err := engine.Statistics(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func GetCount(c *gin.Context) {
+	var clause *models.QueryClause
+	if q := c.Query("q"); q != "" {
+		clause = &models.QueryClause{QueryString: q}
+	}
+	resp, err := engine.Count(c.Param("index"), clause)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func PostCount(c *gin.Context) {
+	var reqBody models.CountRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if reqBody.Query != nil {
+		if err := reqBody.Query.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	resp, err := engine.Count(c.Param("index"), reqBody.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 func GetSearch(c *gin.Context) {
 	idxName := c.Param("index")
 	if idxName == "" {
@@ -47,7 +98,7 @@ func GetSearch(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "query is required"})
 		return
 	}
-	index, err := reg.IndexByName(idxName)
+	index, err := reg.SearchIndex(idxName)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -64,6 +115,10 @@ func GetSearch(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid offset: %s", err.Error())})
 			return
 		}
+		if offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset: must not be negative"})
+			return
+		}
 	}
 	if limitStr != "" {
 		limit, err = strconv.Atoi(limitStr)
@@ -74,3 +129,53 @@ func GetSearch(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, index.Query(query, offset, limit))
 }
+
+func PostSearch(c *gin.Context) {
+	index, err := reg.SearchIndex(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var reqBody models.SearchRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := reqBody.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := index.QueryRequest(&reqBody)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func GetSuggest(c *gin.Context) {
+	index, err := reg.SearchIndex(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	size := 0
+	if sizeStr := c.Query("size"); sizeStr != "" {
+		size, err = strconv.Atoi(sizeStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid size: %s", err.Error())})
+			return
+		}
+	}
+	resp, err := index.Suggest(query, c.Query("field"), size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
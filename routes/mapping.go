@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"Scout.go/engine"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func PutMapping(c *gin.Context) {
+	raw, err := c.GetRawData()
+	if err != nil || len(raw) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mapping document is required"})
+		return
+	}
+	resp, err := engine.PutMapping(c.Param("index"), raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func GetMapping(c *gin.Context) {
+	resp, err := engine.GetMapping(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func DeleteMapping(c *gin.Context) {
+	resp, err := engine.DeleteMapping(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
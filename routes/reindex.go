@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"Scout.go/engine"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func Reindex(c *gin.Context) {
+	resp, err := engine.Reindex(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, resp)
+}
+
+func GetReindexStatus(c *gin.Context) {
+	resp, err := engine.ReindexStatus(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"Scout.go/engine"
+	"Scout.go/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func PutRules(c *gin.Context) {
+	var reqBody models.RuleSet
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := reqBody.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := engine.PutRules(c.Param("index"), reqBody)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func GetRules(c *gin.Context) {
+	resp, err := engine.GetRules(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func DeleteRules(c *gin.Context) {
+	resp, err := engine.DeleteRules(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
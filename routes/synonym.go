@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"Scout.go/engine"
+	"Scout.go/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func PutSynonyms(c *gin.Context) {
+	var reqBody models.SynonymSet
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := reqBody.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := engine.PutSynonyms(c.Param("index"), reqBody)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func GetSynonyms(c *gin.Context) {
+	resp, err := engine.GetSynonyms(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func DeleteSynonyms(c *gin.Context) {
+	resp, err := engine.DeleteSynonyms(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
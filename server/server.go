@@ -47,7 +47,37 @@ func StartServer(log *zap.Logger) {
 	// route setup - start
 	router.GET("/ping", routes.Ping)
 	router.GET("/indexes", routes.GetIndexes)
+	router.DELETE("/indexes/:index", routes.DeleteIndex)
 	router.GET("/search/:index/:query/:offset/:limit", routes.GetSearch)
+	router.POST("/indexes/:index/_search", routes.PostSearch)
+	router.GET("/indexes/:index/_suggest", routes.GetSuggest)
+	router.GET("/indexes/:index/_count", routes.GetCount)
+	router.POST("/indexes/:index/_count", routes.PostCount)
+	router.GET("/indexes/:index/_stats", routes.GetIndexStatistics)
+	router.GET("/indexes/:index/_doc/:id", routes.GetDocument)
+	router.PUT("/indexes/:index/_doc/:id", routes.PutDocument)
+	router.PATCH("/indexes/:index/_doc/:id", routes.PatchDocument)
+	router.DELETE("/indexes/:index/_doc/:id", routes.DeleteDocument)
+	router.POST("/indexes/:index/_bulk", routes.PostBulk)
+	router.POST("/indexes/:index/_bulk_delete", routes.PostBulkDelete)
+	router.PUT("/indexes/:index/synonyms", routes.PutSynonyms)
+	router.GET("/indexes/:index/synonyms", routes.GetSynonyms)
+	router.DELETE("/indexes/:index/synonyms", routes.DeleteSynonyms)
+	router.PUT("/indexes/:index/rules", routes.PutRules)
+	router.GET("/indexes/:index/rules", routes.GetRules)
+	router.DELETE("/indexes/:index/rules", routes.DeleteRules)
+	router.PUT("/indexes/:index/mapping", routes.PutMapping)
+	router.GET("/indexes/:index/mapping", routes.GetMapping)
+	router.DELETE("/indexes/:index/mapping", routes.DeleteMapping)
+	router.PUT("/indexes/:index/dictionary", routes.PutDictionary)
+	router.GET("/indexes/:index/dictionary", routes.GetDictionary)
+	router.POST("/indexes/:index/_reindex", routes.Reindex)
+	router.GET("/indexes/:index/_reindex", routes.GetReindexStatus)
+	router.GET("/aliases", routes.GetAliases)
+	router.PUT("/aliases/:alias", routes.PutAlias)
+	router.GET("/aliases/:alias", routes.GetAlias)
+	router.DELETE("/aliases/:alias", routes.DeleteAlias)
+	router.POST("/aliases/:alias/_swap", routes.SwapAlias)
 	router.GET("/stats", routes.GetIndexStats)
 	router.PUT("/config", routes.PutConfig)
 	router.POST("/binlog", routes.PostDbConfigPerIndex)
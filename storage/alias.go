@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"github.com/blevesearch/bleve/v2"
+	"sort"
+)
+
+// NewIndexAlias searches several indexes as one. Queries are built with the config, synonyms and rules
+// of the first index, the view is only meant for reading.
+func NewIndexAlias(members []*Index) *Index {
+	first := members[0]
+	return &Index{
+		indexMapping: first.Mapping(),
+		logger:       first.logger,
+		indexPath:    first.Path(),
+		name:         first.name,
+		members:      members,
+	}
+}
+
+// searchMembers holds every member steady while the request fans out, so a reindex cannot swap one away.
+// The read locks are taken in name order whatever the order of the alias, a writer waiting on one member
+// would otherwise deadlock two views that lock the same members the other way round.
+func (i *Index) searchMembers(searchRequest *bleve.SearchRequest) (*bleve.SearchResult, error) {
+	members := lockOrder(i.members)
+	indexes := make([]bleve.Index, 0, len(members))
+	for _, member := range members {
+		member.mu.RLock()
+		defer member.mu.RUnlock()
+		indexes = append(indexes, member.index)
+	}
+	return bleve.NewIndexAlias(indexes...).Search(searchRequest)
+}
+
+// lockOrder sorts the members by name and drops repeats, a read lock must not be taken twice.
+func lockOrder(members []*Index) []*Index {
+	ordered := make([]*Index, 0, len(members))
+	seen := make(map[*Index]bool, len(members))
+	for _, member := range members {
+		if !seen[member] {
+			seen[member] = true
+			ordered = append(ordered, member)
+		}
+	}
+	sort.Slice(ordered, func(a, b int) bool {
+		return ordered[a].name < ordered[b].name
+	})
+	return ordered
+}
+
+func (i *Index) getMember(id string) (map[string]interface{}, error) {
+	var err error
+	for _, member := range i.members {
+		var doc map[string]interface{}
+		if doc, err = member.Get(id); err == nil {
+			return doc, nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"Scout.go/models"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestLockOrder(t *testing.T) {
+	a, b, c := &Index{name: "a"}, &Index{name: "b"}, &Index{name: "c"}
+	tests := []struct {
+		name    string
+		members []*Index
+		want    []*Index
+	}{
+		{"sorted", []*Index{a, b, c}, []*Index{a, b, c}},
+		{"reversed", []*Index{c, b, a}, []*Index{a, b, c}},
+		{"repeated member", []*Index{b, a, b}, []*Index{a, b}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lockOrder(tt.members); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lockOrder() = %v, want %v", names(got), names(tt.want))
+			}
+		})
+	}
+}
+
+func TestIndexAlias(t *testing.T) {
+	config := models.IndexMapConfig{Searchable: []models.IndexSearchable{{Field: "title", Type: models.String}}}
+	config.Index = "alias_v1"
+	v1 := newTestIndex(t, config, map[string]interface{}{"id": "1", "title": "red shoe"}, map[string]interface{}{"id": "2", "title": "blue boot"})
+	config.Index = "alias_v2"
+	v2 := newTestIndex(t, config, map[string]interface{}{"id": "3", "title": "green shoe"})
+	alias := NewIndexAlias([]*Index{v2, v1})
+
+	resp, err := searchJSON(t, alias, `{"query":{"match":{"field":"title","query":"shoe"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := hitIds(resp)
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, []string{"1", "3"}) || resp["total"] != uint64(2) {
+		t.Errorf("alias hits = %v of %v, want [1 3] of 2", got, resp["total"])
+	}
+	for id, want := range map[string]string{"1": "red shoe", "3": "green shoe"} {
+		if doc, err := alias.Get(id); err != nil || doc["title"] != want {
+			t.Errorf("Get(%s) = %v, %v, want title %s", id, doc, err, want)
+		}
+	}
+	if _, err := alias.Get("4"); err == nil {
+		t.Error("Get found a document no member holds")
+	}
+}
+
+func names(members []*Index) []string {
+	res := make([]string, 0, len(members))
+	for _, member := range members {
+		res = append(res, member.name)
+	}
+	return res
+}
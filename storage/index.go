@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"Scout.go/dsl"
 	"Scout.go/errors"
 	"Scout.go/internal"
 	"Scout.go/log"
@@ -11,36 +12,87 @@ import (
 	"github.com/blevesearch/bleve/v2"
 	"github.com/blevesearch/bleve/v2/index/scorch"
 	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search"
+	bleveQuery "github.com/blevesearch/bleve/v2/search/query"
 	bleveindex "github.com/blevesearch/bleve_index_api"
+	"github.com/goccy/go-json"
 	"go.uber.org/zap"
+	"net"
 	"os"
-	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
 
+const (
+	defaultLimit = 10
+	maxLimit     = 1000
+
+	// suggestOverFetch leaves room for duplicate values when collecting suggestions
+	suggestOverFetch = 3
+)
+
+var allFields = []string{"*"}
+
+var lastIndexedKey = []byte("_scout_last_indexed")
+
 type Index struct {
 	indexMapping *mapping.IndexMappingImpl
 	logger       *log.BaseLog
 
+	// mu guards the bleve index so a reindex can swap it underneath readers and writers
+	mu        sync.RWMutex
 	index     bleve.Index
 	indexPath string
+	name      string
+
+	// mirror receives every write while a reindex copies the documents into it
+	mirror    bleve.Index
+	reindexMu sync.Mutex
+	reindex   models.ReindexStatus
+	// cancel stops the running rebuild, rebuilt is closed once the rebuild let go of the index
+	cancel  chan struct{}
+	rebuilt chan struct{}
+
+	// ids serialises read-modify-write cycles per document
+	ids idLocks
+
+	// members is set on a read only view that searches several indexes as one
+	members []*Index
 }
 
 func NewIndex(config *models.IndexMapConfig) (*Index, error) {
-	dir := util.IndexPath(config.Index)
-	mapper, err := scoutmap.NewIndexMapping(config)
+	mapper, err := IndexMapping(config)
 	if err != nil {
 		return nil, err
 	}
-	idx, err := createIndex(dir, mapper, log.AppLog)
-	if err != nil {
-		return nil, err
+	return NewIndexUsing(config.Index, mapper)
+}
+
+// NewIndexUsing opens the index, a missing one is created with the given mapping.
+func NewIndexUsing(name string, mapper *mapping.IndexMappingImpl) (*Index, error) {
+	dir := indexDir(name)
+	removeOrphanedDirs(name, dir, log.AppLog)
+	return createIndex(name, dir, mapper, log.AppLog)
+}
+
+// indexDir is where the index lives, a reindex moves it to a new directory recorded in the path store.
+func indexDir(name string) string {
+	if dir, err := internal.DB.GetJson(name, internal.IndexPathStore); err == nil && dir != "" {
+		return dir
+	}
+	return util.IndexPath(name)
+}
+
+// IndexMapping prefers the raw bleve mapping stored for the index over the one generated from its config.
+func IndexMapping(config *models.IndexMapConfig) (*mapping.IndexMappingImpl, error) {
+	if raw, err := internal.DB.Get(config.Index, internal.MappingStore); err == nil {
+		return scoutmap.NewIndexMappingFromBytes(raw)
 	}
-	return idx, nil
+	return scoutmap.NewIndexMapping(config)
 }
 
-func createIndex(dir string, indexMapping *mapping.IndexMappingImpl, logger *log.BaseLog) (*Index, error) {
+func createIndex(name, dir string, indexMapping *mapping.IndexMappingImpl, logger *log.BaseLog) (*Index, error) {
 	var index bleve.Index
 
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
@@ -65,12 +117,23 @@ func createIndex(dir string, indexMapping *mapping.IndexMappingImpl, logger *log
 	return &Index{
 		index:        index,
 		indexPath:    dir,
+		name:         name,
 		indexMapping: indexMapping,
 		logger:       logger,
+		reindex:      models.ReindexStatus{Index: name, State: models.ReindexIdle},
 	}, nil
 }
 
+// Close cancels a running reindex before closing, so the rebuild cannot swap in an index after it.
 func (i *Index) Close() error {
+	i.StopReindex()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.mirror != nil {
+		_ = i.mirror.Close()
+	}
 	if err := i.index.Close(); err != nil {
 		i.logger.Error(errors.ErrCloseIndex.Error(), zap.Error(err))
 		return err
@@ -79,7 +142,14 @@ func (i *Index) Close() error {
 	return nil
 }
 
+// Get returns the stored fields of a document, dotted paths are nested back into sub-documents.
 func (i *Index) Get(id string) (map[string]interface{}, error) {
+	if i.members != nil {
+		return i.getMember(id)
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
 	doc, err := i.index.Document(id)
 	if err != nil {
 		i.logger.Error(errors.ErrNoDoc.Error(), zap.String("id", id), zap.Error(err))
@@ -91,6 +161,19 @@ func (i *Index) Get(id string) (map[string]interface{}, error) {
 		return nil, err
 	}
 
+	return nestFields(storedFields(doc)), nil
+}
+
+func nestFields(flat map[string]interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, len(flat))
+	for path, value := range flat {
+		setFieldValue(fields, path, value)
+	}
+	return fields
+}
+
+// storedFields reads the stored values of a document, repeated fields become arrays.
+func storedFields(doc bleveindex.Document) map[string]interface{} {
 	fields := make(map[string]interface{}, 0)
 	doc.VisitFields(func(field bleveindex.Field) {
 		var v interface{}
@@ -107,6 +190,26 @@ func (i *Index) Get(id string) (map[string]interface{}, error) {
 			if err == nil {
 				v = d.Format(time.RFC3339Nano)
 			}
+		case bleveindex.BooleanField:
+			b, err := field.Boolean()
+			if err == nil {
+				v = b
+			}
+		case bleveindex.GeoPointField:
+			lon, err := field.Lon()
+			if err == nil {
+				if lat, err := field.Lat(); err == nil {
+					v = []float64{lon, lat}
+				}
+			}
+		case ipField:
+			ip, err := field.IP()
+			if err == nil {
+				v = ip.String()
+			}
+		}
+		if v == nil {
+			return
 		}
 		existing, existed := fields[field.Name()]
 		if existed {
@@ -123,12 +226,23 @@ func (i *Index) Get(id string) (map[string]interface{}, error) {
 			fields[field.Name()] = v
 		}
 	})
+	return fields
+}
 
-	return fields, nil
+type ipField interface {
+	IP() (net.IP, error)
 }
 
 func (i *Index) Search(searchRequest *bleve.SearchRequest) (*bleve.SearchResult, error) {
-	searchResult, err := i.index.Search(searchRequest)
+	var searchResult *bleve.SearchResult
+	var err error
+	if i.members != nil {
+		searchResult, err = i.searchMembers(searchRequest)
+	} else {
+		i.mu.RLock()
+		searchResult, err = i.index.Search(searchRequest)
+		i.mu.RUnlock()
+	}
 	if err != nil {
 		i.logger.Error(errors.ErrSearchDoc.Error(), zap.Any("search_request", searchRequest), zap.Error(err))
 		return nil, err
@@ -138,27 +252,57 @@ func (i *Index) Search(searchRequest *bleve.SearchRequest) (*bleve.SearchResult,
 }
 
 func (i *Index) Index(id string, fields map[string]interface{}) error {
-	if err := i.index.Index(id, fields); err != nil {
+	unlock := i.ids.lock(id)
+	defer unlock()
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	batch := i.index.NewBatch()
+	mirrored := i.mirrorBatch()
+	if err := batch.Index(id, fields); err != nil {
 		i.logger.Error(errors.ErrIndexDoc.Error(), zap.String("id", id), zap.Error(err))
 		return err
 	}
+	if mirrored != nil {
+		if err := mirrored.Index(id, fields); err != nil {
+			i.logger.Error(errors.ErrIndexDoc.Error(), zap.String("id", id), zap.String("mirror", i.name), zap.Error(err))
+		}
+	}
+	if err := i.bumpVersions([]string{id}, batch, mirrored); err != nil {
+		return err
+	}
+	markIndexed(batch, mirrored)
+	if err := i.index.Batch(batch); err != nil {
+		i.logger.Error(errors.ErrIndexDoc.Error(), zap.String("id", id), zap.Error(err))
+		return err
+	}
+	i.applyMirror(mirrored)
 
 	return nil
 }
 
 func (i *Index) Delete(id string) error {
-	if err := i.index.Delete(id); err != nil {
-		i.logger.Error(errors.ErrDeleteDoc.Error(), zap.String("id", id), zap.Error(err))
-		return err
-	}
-
-	return nil
+	_, err := i.BulkDelete([]string{id})
+	return err
 }
 
 func (i *Index) BulkIndex(docs []map[string]interface{}) (int, error) {
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		if id, ok := doc["id"].(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	unlock := i.ids.lock(ids...)
+	defer unlock()
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
 	batch := i.index.NewBatch()
+	mirrored := i.mirrorBatch()
 
 	count := 0
+	indexed := make([]string, 0, len(docs))
 
 	for _, doc := range docs {
 		id, ok := doc["id"].(string)
@@ -178,68 +322,165 @@ func (i *Index) BulkIndex(docs []map[string]interface{}) (int, error) {
 			i.logger.Error(errors.ErrIndexBatch.Error(), zap.String("id", id), zap.Error(err))
 			continue
 		}
+		if mirrored != nil {
+			if err := mirrored.Index(id, fields); err != nil {
+				i.logger.Error(errors.ErrIndexBatch.Error(), zap.String("id", id), zap.String("mirror", i.name), zap.Error(err))
+			}
+		}
+		indexed = append(indexed, id)
 		count++
 	}
+	if count <= 0 {
+		err := errors.ErrNoUpdate
+		i.logger.Error(errors.ErrNoUpdate.Error(), zap.Any("count", count), zap.Error(err))
+		return count, err
+	}
+	if err := i.bumpVersions(indexed, batch, mirrored); err != nil {
+		return 0, err
+	}
+	markIndexed(batch, mirrored)
 
 	err := i.index.Batch(batch)
 	if err != nil {
 		i.logger.Error(errors.ErrIndexBatch.Error(), zap.Int("count", count), zap.Error(err))
 		return count, err
 	}
-
-	if count <= 0 {
-		err := errors.ErrNoUpdate
-		i.logger.Error(errors.ErrNoUpdate.Error(), zap.Any("count", count), zap.Error(err))
-		return count, err
-	}
+	i.applyMirror(mirrored)
 
 	return count, nil
 }
 
 func (i *Index) BulkDelete(ids []string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	unlock := i.ids.lock(ids...)
+	defer unlock()
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
 	batch := i.index.NewBatch()
+	mirrored := i.mirrorBatch()
 
 	count := 0
 
 	for _, id := range ids {
 		batch.Delete(id)
+		batch.DeleteInternal(versionKey(id))
+		if mirrored != nil {
+			mirrored.Delete(id)
+			mirrored.DeleteInternal(versionKey(id))
+		}
 		count++
 	}
+	markIndexed(batch, mirrored)
 
 	err := i.index.Batch(batch)
 	if err != nil {
 		i.logger.Error(errors.ErrDeleteDoc.Error(), zap.Int("count", count), zap.Error(err))
 		return count, err
 	}
+	i.applyMirror(mirrored)
 
 	return count, nil
 }
 
+// markIndexed records on the batches when the index was last written, the time is committed together with
+// the documents so it survives restarts without a write of its own.
+func markIndexed(batches ...*bleve.Batch) {
+	now := []byte(time.Now().Format(time.RFC3339Nano))
+	for _, batch := range batches {
+		if batch != nil {
+			batch.SetInternal(lastIndexedKey, now)
+		}
+	}
+}
+
+// mirrorBatch starts a batch on the index being rebuilt, nil when no reindex runs. The caller holds the read lock.
+func (i *Index) mirrorBatch() *bleve.Batch {
+	if i.mirror == nil {
+		return nil
+	}
+	return i.mirror.NewBatch()
+}
+
+func (i *Index) applyMirror(batch *bleve.Batch) {
+	if batch == nil || batch.Size() == 0 {
+		return
+	}
+	if err := i.mirror.Batch(batch); err != nil {
+		i.logger.Error(errors.ErrIndexBatch.Error(), zap.String("mirror", i.name), zap.Error(err))
+	}
+}
+
 func (i *Index) Mapping() *mapping.IndexMappingImpl {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
 	return i.indexMapping
 }
 
 func (i *Index) Stats() map[string]interface{} {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
 	return i.index.StatsMap()
 }
 
 func (i *Index) Name() string {
-	return filepath.Base(i.indexPath)
+	return i.name
 }
 
 func (i *Index) Path() string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
 	return i.indexPath
 }
 
-func (i *Index) PrepareAndIndex(data []map[string]interface{}) error {
+func (i *Index) Config() (*models.IndexMapConfig, error) {
+	var config models.IndexMapConfig
+	err := internal.DB.GetMap(i.Name(), &config, internal.IndexConfigStore)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// synonyms loads the synonym groups of the index, an index without any gives an empty set.
+func (i *Index) synonyms() dsl.Synonyms {
+	var set models.SynonymSet
+	if err := internal.DB.GetMap(i.Name(), &set, internal.SynonymStore); err != nil {
+		return nil
+	}
+	return dsl.NewSynonyms(set.Groups)
+}
+
+// expand adds the synonym variants to the query and spreads the text searching _all over the fields left out of it.
+func (i *Index) expand(q bleveQuery.Query, config *models.IndexMapConfig) bleveQuery.Query {
+	return dsl.SpreadOverFields(dsl.ExpandSynonyms(q, i.synonyms()), dsl.SeparateFields(config))
+}
+
+// merchandising merges the rules of the index matching the query text, nil when none applies.
+func (i *Index) merchandising(text string) *dsl.Merchandising {
+	var set models.RuleSet
+	if err := internal.DB.GetMap(i.Name(), &set, internal.RuleStore); err != nil {
+		return nil
+	}
+	return dsl.NewMerchandising(set.Rules, text, time.Now())
+}
+
+// PrepareAndIndex normalizes the rows with the config of the index and indexes them, it returns how many
+// documents were indexed. Rows without a usable unique id are skipped.
+func (i *Index) PrepareAndIndex(data []map[string]interface{}) (int, error) {
 	var indexMapConfig models.IndexMapConfig
 	err := internal.DB.Find(&indexMapConfig, i.Name(), 1, internal.IndexConfigStore)
 	if err != nil {
 		log.AppLog.E(i.Name(), "error getting index config", zap.Error(err))
-		return err
+		return 0, err
 	}
 	if len(data) == 0 {
-		return errors.ErrNoDoc
+		return 0, errors.ErrNoDoc
 	}
 	var wg sync.WaitGroup
 	var mu sync.Mutex
@@ -248,12 +489,14 @@ func (i *Index) PrepareAndIndex(data []map[string]interface{}) error {
 	data = [{fields...}]
 	norm = [{id:string,fields:{fields...}}]
 	*/
-	norm := make([]map[string]interface{}, 0)
-	for _, d := range data {
+	// every document keeps its slot, so when an id repeats the last version wins
+	slots := make([]map[string]interface{}, len(data))
+	for pos, d := range data {
 		wg.Add(1)
-		go func(t map[string]interface{}, w *sync.WaitGroup, m *sync.Mutex, r *[]map[string]interface{}, c *models.IndexMapConfig) {
+		go func(t map[string]interface{}, w *sync.WaitGroup, m *sync.Mutex, r *map[string]interface{}, c *models.IndexMapConfig) {
 			defer w.Done()
 			m.Lock()
+			defer m.Unlock()
 			v, ok := t[c.UniqueId]
 			if ok {
 				vs, er := util.ToString(v)
@@ -261,66 +504,464 @@ func (i *Index) PrepareAndIndex(data []map[string]interface{}) error {
 					log.AppLog.E(c.Index, "unique id expected as string", zap.Any("id", v))
 					return
 				}
+				expandJSONColumns(t, c)
+				geoPoints(t, c)
+				normalizeArrays(t, c)
 				n := map[string]interface{}{
 					"id":     vs,
 					"fields": t,
 				}
-				*r = append(*r, n)
+				*r = n
 			} else {
 				log.AppLog.E(c.Index, "unique ID not found in index mapping", zap.String("id", c.UniqueId), zap.Any("data", t))
 			}
-			m.Unlock()
-		}(d, &wg, &mu, &norm, &indexMapConfig)
+		}(d, &wg, &mu, &slots[pos], &indexMapConfig)
 	}
 	wg.Wait()
 
+	norm := make([]map[string]interface{}, 0, len(slots))
+	for _, n := range slots {
+		if n != nil {
+			norm = append(norm, n)
+		}
+	}
+
 	count, err := i.BulkIndex(util.MakeUniqueById(norm))
 	if err != nil {
-		return err
+		return count, err
 	}
 	log.AppLog.I(i.Name(), "bulk indexing completed...", zap.Int("count", count))
 
-	return nil
+	return count, nil
+}
+
+// expandJSONColumns decodes the JSON text columns that dotted field paths point into.
+func expandJSONColumns(doc map[string]interface{}, config *models.IndexMapConfig) {
+	for _, searchable := range config.Searchable {
+		root, _, nested := strings.Cut(searchable.Field, ".")
+		if !nested {
+			continue
+		}
+		if parsed, ok := util.ParseJSON(doc[root]); ok {
+			doc[root] = parsed
+		}
+	}
+}
+
+// fieldValue resolves a dotted path through nested maps.
+func fieldValue(doc map[string]interface{}, path string) (interface{}, bool) {
+	elements := strings.Split(path, ".")
+	current := doc
+	for _, element := range elements[:len(elements)-1] {
+		next, ok := current[element].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+	v, ok := current[elements[len(elements)-1]]
+	return v, ok
+}
+
+// setFieldValue assigns a dotted path, creating the nested maps on the way.
+func setFieldValue(doc map[string]interface{}, path string, value interface{}) {
+	elements := strings.Split(path, ".")
+	current := doc
+	for _, element := range elements[:len(elements)-1] {
+		next, ok := current[element].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[element] = next
+		}
+		current = next
+	}
+	current[elements[len(elements)-1]] = value
+}
+
+// geoPoints builds geopoint fields from their lat / lon columns, rows without a valid pair get no point.
+func geoPoints(doc map[string]interface{}, config *models.IndexMapConfig) {
+	for _, searchable := range config.Searchable {
+		if searchable.Type != models.GeoPoint || searchable.Lat == "" {
+			continue
+		}
+		latValue, _ := fieldValue(doc, searchable.Lat)
+		lonValue, _ := fieldValue(doc, searchable.Lon)
+		lat, latErr := util.ToFloat(latValue)
+		lon, lonErr := util.ToFloat(lonValue)
+		if latErr != nil || lonErr != nil {
+			continue
+		}
+		setFieldValue(doc, searchable.Field, map[string]interface{}{"lat": lat, "lon": lon})
+	}
+}
+
+// normalizeArrays turns multi-valued columns, given as JSON arrays or comma separated strings, into slices.
+func normalizeArrays(doc map[string]interface{}, config *models.IndexMapConfig) {
+	for _, searchable := range config.Searchable {
+		v, ok := fieldValue(doc, searchable.Field)
+		if !searchable.IsArray() || !ok || v == nil {
+			continue
+		}
+		values := arrayValues(v)
+		if searchable.Type == models.NumberArray {
+			numbers := make([]interface{}, 0, len(values))
+			for _, value := range values {
+				if f, err := util.ToFloat(value); err == nil {
+					numbers = append(numbers, f)
+				}
+			}
+			values = numbers
+		}
+		setFieldValue(doc, searchable.Field, values)
+	}
+}
+
+func arrayValues(v interface{}) []interface{} {
+	if values, ok := v.([]interface{}); ok {
+		return values
+	}
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return []interface{}{v}
+	}
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") {
+		var values []interface{}
+		if err := json.Unmarshal([]byte(s), &values); err == nil {
+			return values
+		}
+	}
+	values := make([]interface{}, 0)
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
 }
 
 func (i *Index) Query(query string, offset, limit int) map[string]interface{} {
 	start := time.Now()
 
-	h := 0
-	c := make([]map[string]interface{}, 0)
+	limit = clampLimit(limit)
 
-	q := bleve.NewQueryStringQuery(query)
+	var q bleveQuery.Query = bleve.NewQueryStringQuery(query)
+	config, err := i.Config()
+	if err == nil && dsl.IsSimpleQuery(query) {
+		if dsl.HasFieldBoosts(config) {
+			// weighted fields need the words matched per field instead of on the composite field
+			q, err = dsl.NewMultiMatchQuery(&models.MultiMatchClause{Query: query}, config)
+			if err != nil {
+				q = bleve.NewQueryStringQuery(query)
+			}
+		} else if dsl.TypoEnabled(config) {
+			q = dsl.NewTypoQuery(query, "", false, config)
+		}
+	}
+	q = i.expand(q, config)
+	rank := ranking{merchandising: i.merchandising(query)}
+	if config != nil {
+		rank.scoring = config.Scoring
+	}
+	if rank.merchandising != nil {
+		q = rank.merchandising.Boost(rank.merchandising.Exclude(q))
+	}
 	req := bleve.NewSearchRequestOptions(q, limit, offset, false)
-	//req := bleve.NewSearchRequest(q)
-	//req.Size = 50
+	req.Fields = allFields
+	if rank.active() {
+		req.From, req.Size = 0, rank.window(offset, limit)
+	}
 	res, err := i.Search(req)
-	if err == nil {
-		var wg sync.WaitGroup
-		var m sync.Mutex
-
-		h = res.Hits.Len()
-
-		for _, hit := range res.Hits {
-			wg.Add(1)
-			go func(id string, mx *sync.Mutex, w *sync.WaitGroup, cl *[]map[string]interface{}) {
-				defer w.Done()
-				m.Lock()
-				doc, err := i.Get(id)
-				if err == nil {
-					c = append(c, doc)
-				}
-				m.Unlock()
-			}(hit.ID, &m, &wg, &c)
-		}
-		wg.Wait()
-	} else {
+	if err == nil && rank.active() {
+		err = i.rank(res, rank, config, offset, limit)
+	}
+	if err != nil {
 		fmt.Printf("[Error] ❌ %v %s\n", err.Error(), query)
+		res = &bleve.SearchResult{}
+	}
+
+	resp := paginate(res, offset, limit)
+	resp["execution"] = util.Elapsed(start)
+	resp["data"] = hydrate(res)
+	resp["query"] = query
+	return resp
+}
+
+func (i *Index) QueryRequest(request *models.SearchRequest) (map[string]interface{}, error) {
+	start := time.Now()
+
+	config, err := i.Config()
+	if err != nil {
+		return nil, err
+	}
+	q, err := dsl.NewQuery(request.Query, config)
+	if err != nil {
+		return nil, err
+	}
+	q = i.expand(q, config)
+	rank := ranking{scoring: request.Scoring, merchandising: i.merchandising(dsl.QueryText(request.Query))}
+	if rank.scoring == nil {
+		rank.scoring = config.Scoring
+	}
+	if len(request.Sort) > 0 || request.Cursor != "" {
+		// ranking only reorders relevance ranked pages, explicit sorts and cursors keep their order
+		rank.scoring = nil
+		if rank.merchandising != nil {
+			rank.merchandising = rank.merchandising.Unpinned()
+		}
+	}
+	if rank.merchandising != nil {
+		q = rank.merchandising.Boost(rank.merchandising.Exclude(q))
+	}
+	limit := clampLimit(request.Limit)
+	req := bleve.NewSearchRequestOptions(q, limit, request.Offset, false)
+	req.Fields = allFields
+	req.Facets, err = dsl.NewFacets(request.Facets)
+	if err != nil {
+		return nil, err
+	}
+	if err := scoutmap.CheckSort(i.Mapping(), request.Sort, config); err != nil {
+		return nil, err
+	}
+	order, err := dsl.NewSort(request.Sort, config)
+	if err != nil {
+		return nil, err
+	}
+	req.SortByCustom(order)
+	if rank.active() {
+		req.From, req.Size = 0, rank.window(request.Offset, limit)
+	}
+	if request.Cursor != "" {
+		req.SearchAfter, req.SearchBefore, err = dsl.DecodeCursor(request.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var highlightFields []string
+	if request.Highlight != nil {
+		highlightFields, err = highlightFieldsOf(request.Highlight, config)
+		if err != nil {
+			return nil, err
+		}
+		req.IncludeLocations = true
+	}
+	res, err := i.Search(req)
+	if err != nil {
+		return nil, err
+	}
+	if rank.active() {
+		if err := i.rank(res, rank, config, request.Offset, limit); err != nil {
+			return nil, err
+		}
+	}
+
+	dsl.FillHistograms(request.Facets, res.Facets)
+	data := hydrate(res)
+	if request.Highlight != nil {
+		highlighter := dsl.NewHighlighter(request.Highlight)
+		for n, hit := range res.Hits {
+			data[n]["_highlight"] = dsl.Highlight(hit, highlighter, highlightFields, request.Highlight.Fragments)
+		}
+	}
+
+	resp := paginate(res, request.Offset, limit)
+	if request.Cursor != "" {
+		// a cursor page has no absolute offset, a full page is the only hint of more
+		resp["has_more"] = res.Hits.Len() == limit
+	}
+	if n := res.Hits.Len(); n > 0 && !rank.active() {
+		resp["next_cursor"] = dsl.NextCursor(dsl.CursorKeys(res.Hits[n-1], order))
+		resp["prev_cursor"] = dsl.PrevCursor(dsl.CursorKeys(res.Hits[0], order))
+	}
+	resp["execution"] = util.Elapsed(start)
+	resp["data"] = data
+	resp["facets"] = res.Facets
+	resp["query"] = request.Query
+	return resp, nil
+}
+
+// Suggest returns distinct completions for the typed text from the autocomplete enabled fields.
+func (i *Index) Suggest(text, field string, size int) (map[string]interface{}, error) {
+	start := time.Now()
+
+	config, err := i.Config()
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]string, 0)
+	for _, searchable := range config.Searchable {
+		if searchable.Autocomplete && (field == "" || field == searchable.Field) {
+			fields = append(fields, searchable.Field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, errors.ErrNoAutocomplete
+	}
+	size = clampLimit(size)
+
+	req := bleve.NewSearchRequestOptions(dsl.NewSuggestQuery(text, fields), size*suggestOverFetch, 0, false)
+	req.Fields = fields
+	res, err := i.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	suggestions := make([]map[string]interface{}, 0, size)
+OUTER:
+	for _, hit := range res.Hits {
+		for _, f := range fields {
+			value, ok := hit.Fields[f].(string)
+			if !ok || seen[strings.ToLower(value)] || !dsl.SuggestMatches(text, value) {
+				continue
+			}
+			seen[strings.ToLower(value)] = true
+			suggestions = append(suggestions, map[string]interface{}{
+				"text":  value,
+				"field": f,
+				"_id":   hit.ID,
+			})
+			if len(suggestions) == size {
+				break OUTER
+			}
+		}
 	}
 
 	return map[string]interface{}{
-		"execution": util.Elapsed(start),
-		"data":      c,
-		"query":     query,
-		"hits":      h,
+		"execution":   util.Elapsed(start),
+		"query":       text,
+		"suggestions": suggestions,
+	}, nil
+}
+
+// clampLimit keeps the page size between 1 and maxLimit, 0 falls back to defaultLimit.
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return defaultLimit
+	}
+	if limit > maxLimit {
+		return maxLimit
+	}
+	return limit
+}
+
+// paginate reports the page window together with the true number of matches.
+func paginate(res *bleve.SearchResult, offset, limit int) map[string]interface{} {
+	return map[string]interface{}{
+		"hits":      res.Hits.Len(),
+		"total":     res.Total,
+		"max_score": res.MaxScore,
+		"took":      res.Took.String(),
+		"offset":    offset,
+		"limit":     limit,
+		"has_more":  uint64(offset+res.Hits.Len()) < res.Total,
+	}
+}
+
+// ranking reorders the top window of a relevance ranked search after bleve has scored it.
+type ranking struct {
+	scoring       *models.Scoring
+	merchandising *dsl.Merchandising
+}
+
+func (r ranking) active() bool {
+	return dsl.Rescoring(r.scoring) || (r.merchandising != nil && r.merchandising.Places())
+}
+
+// window is the number of top hits fetched so the requested page can be cut out after ranking, it
+// always holds the whole rescore window so a page crossing its boundary neither repeats nor skips a hit.
+func (r ranking) window(offset, limit int) int {
+	if dsl.Rescoring(r.scoring) && dsl.RescoreWindow(r.scoring) > offset+limit {
+		return dsl.RescoreWindow(r.scoring)
+	}
+	return offset + limit
+}
+
+// rank applies the scoring functions and merchandising pins to the fetched window and cuts the page out of it.
+func (i *Index) rank(res *bleve.SearchResult, r ranking, config *models.IndexMapConfig, offset, limit int) error {
+	if dsl.Rescoring(r.scoring) {
+		window := dsl.RescoreWindow(r.scoring)
+		if window > len(res.Hits) {
+			window = len(res.Hits)
+		}
+		if err := dsl.Rescore(res.Hits[:window], r.scoring, config); err != nil {
+			return err
+		}
+	}
+	if r.merchandising != nil {
+		pinned, err := i.pinned(r.merchandising.PinnedIds())
+		if err != nil {
+			return err
+		}
+		// Exclude keeps pinned documents out of the organic total, only those not already counted are added
+		var added int
+		res.Hits, added = r.merchandising.Pin(res.Hits, pinned)
+		res.Total += uint64(added)
+	}
+	res.MaxScore = 0
+	for _, hit := range res.Hits {
+		if hit.Score > res.MaxScore {
+			res.MaxScore = hit.Score
+		}
+	}
+	if offset > len(res.Hits) {
+		offset = len(res.Hits)
+	}
+	end := offset + limit
+	if end > len(res.Hits) {
+		end = len(res.Hits)
+	}
+	res.Hits = res.Hits[offset:end]
+	return nil
+}
+
+// pinned loads the pinned documents with their stored fields, ids missing from the index are skipped.
+func (i *Index) pinned(ids []string) (map[string]*search.DocumentMatch, error) {
+	pinned := make(map[string]*search.DocumentMatch, len(ids))
+	if len(ids) == 0 {
+		return pinned, nil
+	}
+	req := bleve.NewSearchRequestOptions(bleve.NewDocIDQuery(ids), len(ids), 0, false)
+	req.Fields = allFields
+	res, err := i.Search(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range res.Hits {
+		pinned[hit.ID] = hit
+	}
+	return pinned, nil
+}
+
+// highlightFieldsOf resolves the fields to highlight, defaulting to every string field of the index.
+func highlightFieldsOf(h *models.HighlightRequest, config *models.IndexMapConfig) ([]string, error) {
+	if !config.Highlight {
+		return nil, errors.ErrHighlightOff
+	}
+	if len(h.Fields) > 0 {
+		return h.Fields, nil
+	}
+	fields := make([]string, 0)
+	for _, searchable := range config.Searchable {
+		if searchable.IsText() {
+			fields = append(fields, searchable.Field)
+		}
+	}
+	return fields, nil
+}
+
+// hydrate turns hits into documents in ranking order, the stored fields come along with the search itself.
+// Dotted fields are nested back into objects, the same shape Get returns.
+func hydrate(res *bleve.SearchResult) []map[string]interface{} {
+	c := make([]map[string]interface{}, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		doc := nestFields(hit.Fields)
+		doc["_id"] = hit.ID
+		doc["_score"] = hit.Score
+		c = append(c, doc)
 	}
+	return c
 }
@@ -0,0 +1,905 @@
+package storage
+
+import (
+	"Scout.go/errors"
+	"Scout.go/internal"
+	"Scout.go/log"
+	"Scout.go/models"
+	"Scout.go/util"
+	"encoding/json"
+	"fmt"
+	"github.com/blevesearch/bleve/v2/search"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain keeps the config store and the test indexes in a temporary working directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "scout-storage")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	internal.NewDiskStorage()
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// newTestIndex stores the config and opens its index with the given documents, both go away with the test.
+func newTestIndex(t *testing.T, config models.IndexMapConfig, docs ...map[string]interface{}) *Index {
+	t.Helper()
+	if config.Index == "" {
+		config.Index = strings.ToLower(strings.NewReplacer("/", "_", " ", "_").Replace(t.Name()))
+	}
+	if config.UniqueId == "" {
+		config.UniqueId = "id"
+	}
+	if err := internal.DB.PutMap(config.Index, &config, internal.IndexConfigStore); err != nil {
+		t.Fatal(err)
+	}
+	index, err := NewIndex(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = index.Close()
+		_ = os.RemoveAll(index.Path())
+		_ = internal.DB.Delete(config.Index, internal.IndexConfigStore)
+	})
+	if len(docs) > 0 {
+		if _, err := index.PrepareAndIndex(docs); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return index
+}
+
+// searchJSON runs a JSON search request against the index.
+func searchJSON(t *testing.T, index *Index, body string) (map[string]interface{}, error) {
+	t.Helper()
+	var request models.SearchRequest
+	if err := json.Unmarshal([]byte(body), &request); err != nil {
+		t.Fatal(err)
+	}
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	return index.QueryRequest(&request)
+}
+
+// hitIds lists the ids of the returned documents in order.
+func hitIds(resp map[string]interface{}) []string {
+	ids := make([]string, 0)
+	for _, doc := range resp["data"].([]map[string]interface{}) {
+		ids = append(ids, doc["_id"].(string))
+	}
+	return ids
+}
+
+var shoes = []map[string]interface{}{
+	{"id": "1", "title": "red shoe", "body": "a shoe for running in the rain"},
+	{"id": "2", "title": "shoe red", "body": "red laces"},
+	{"id": "3", "title": "blue boot", "body": "not a shoe"},
+}
+
+func TestPhraseQueriesWithoutHighlight(t *testing.T) {
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{
+		{Field: "title", Type: models.String}, {Field: "body", Type: models.String}}}, shoes...)
+
+	resp, err := searchJSON(t, index, `{"query":{"phrase":{"field":"title","query":"red shoe"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hitIds(resp); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("phrase clause found %v, want [1]", got)
+	}
+	if got := hitIds(index.Query(`title:"red shoe"`, 0, 10)); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("query string phrase found %v, want [1]", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	index := newTestIndex(t, models.IndexMapConfig{Highlight: true, Searchable: []models.IndexSearchable{
+		{Field: "title", Type: models.String}, {Field: "body", Type: models.String}}}, shoes...)
+
+	resp, err := searchJSON(t, index, `{"query":{"match":{"field":"body","query":"rain"}},"highlight":{"pre_tag":"[","post_tag":"]"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := resp["data"].([]map[string]interface{})
+	if len(data) != 1 {
+		t.Fatalf("found %d documents, want 1", len(data))
+	}
+	want := map[string][]string{"body": {"a shoe for running in the [rain]"}}
+	if got := data[0]["_highlight"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("_highlight = %v, want %v", got, want)
+	}
+
+	plain := newTestIndex(t, models.IndexMapConfig{Index: "plain", Searchable: []models.IndexSearchable{{Field: "title", Type: models.String}}}, shoes...)
+	if _, err := searchJSON(t, plain, `{"query":{"match":{"field":"title","query":"shoe"}},"highlight":{}}`); err != errors.ErrHighlightOff {
+		t.Errorf("highlight on an index without it: error = %v, want %v", err, errors.ErrHighlightOff)
+	}
+}
+
+func TestSearchDSL(t *testing.T) {
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{
+		{Field: "title", Type: models.String}, {Field: "sku", Type: models.Keyword},
+		{Field: "price", Type: models.Number}, {Field: "created", Type: models.DateTime}}},
+		map[string]interface{}{"id": "1", "title": "red running shoe", "sku": "RS-1", "price": 50.0, "created": "2024-01-10T00:00:00Z"},
+		map[string]interface{}{"id": "2", "title": "blue running shoe", "sku": "BS-1", "price": 80.0, "created": "2024-02-10T00:00:00Z"},
+		map[string]interface{}{"id": "3", "title": "red boot", "sku": "RB-1", "price": 120.0, "created": "2024-03-10T00:00:00Z"},
+	)
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"term on keyword", `{"query":{"term":{"field":"sku","value":"BS-1"}}}`, []string{"2"}},
+		{"match all words", `{"query":{"match":{"field":"title","query":"red shoe","operator":"and"}}}`, []string{"1"}},
+		{"prefix", `{"query":{"prefix":{"field":"title","value":"boo"}}}`, []string{"3"}},
+		{"wildcard", `{"query":{"wildcard":{"field":"title","value":"b*e"}}}`, []string{"2"}},
+		{"exclusive range", `{"query":{"range":{"field":"price","gt":50,"lt":120}}}`, []string{"2"}},
+		{"date range", `{"query":{"date_range":{"field":"created","gte":"2024-02-01","lt":"2024-03-10"}}}`, []string{"2"}},
+		{"bool", `{"query":{"bool":{"must":[{"match":{"field":"title","query":"red"}}],"must_not":[{"term":{"field":"sku","value":"RB-1"}}]}}}`, []string{"1"}},
+		{"query string with special characters", `{"query":{"query_string":"+title:running -title:blue"}}`, []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := searchJSON(t, index, tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hitIds(resp); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("found %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFacets(t *testing.T) {
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{
+		{Field: "title", Type: models.String}, {Field: "category", Type: models.Keyword},
+		{Field: "price", Type: models.Number}, {Field: "created", Type: models.DateTime}}},
+		map[string]interface{}{"id": "1", "title": "shoe", "category": "shoes", "price": 20.0, "created": "2024-01-10T00:00:00Z"},
+		map[string]interface{}{"id": "2", "title": "shoe", "category": "shoes", "price": 60.0, "created": "2024-01-20T00:00:00Z"},
+		map[string]interface{}{"id": "3", "title": "boot", "category": "boots", "price": 150.0, "created": "2024-02-10T00:00:00Z"},
+	)
+	resp, err := searchJSON(t, index, `{"limit":1,"facets":{
+		"category":{"field":"category"},
+		"price":{"field":"price","numeric_ranges":[{"name":"cheap","max":50},{"name":"mid","min":50,"max":100},{"name":"premium","min":100}]},
+		"month":{"field":"created","date_ranges":[{"name":"january","start":"2024-01-01","end":"2024-02-01"}]}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	facets := resp["facets"].(search.FacetResults)
+
+	terms := map[string]int{}
+	for _, term := range facets["category"].Terms.Terms() {
+		terms[term.Term] = term.Count
+	}
+	if want := map[string]int{"shoes": 2, "boots": 1}; !reflect.DeepEqual(terms, want) {
+		t.Errorf("category facet = %v, want %v", terms, want)
+	}
+	ranges := map[string]int{}
+	for _, r := range facets["price"].NumericRanges {
+		ranges[r.Name] = r.Count
+	}
+	if want := map[string]int{"cheap": 1, "mid": 1, "premium": 1}; !reflect.DeepEqual(ranges, want) {
+		t.Errorf("price facet = %v, want %v", ranges, want)
+	}
+	if got := facets["month"].DateRanges; len(got) != 1 || got[0].Count != 2 {
+		t.Errorf("month facet = %+v, want january with 2", got)
+	}
+	if facets["category"].Total != 3 || resp["hits"] != 1 {
+		t.Errorf("facets count every match, not the page: total %d, hits %v", facets["category"].Total, resp["hits"])
+	}
+}
+
+func TestSort(t *testing.T) {
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{
+		{Field: "title", Type: models.String}, {Field: "price", Type: models.Number}, {Field: "created", Type: models.DateTime}}},
+		map[string]interface{}{"id": "a", "title": "zebra shoe", "price": 30.0, "created": "2024-01-01T00:00:00Z"},
+		map[string]interface{}{"id": "b", "title": "apple shoe", "price": 10.0, "created": "2024-03-01T00:00:00Z"},
+		map[string]interface{}{"id": "c", "title": "mango shoe", "price": 30.0, "created": "2024-02-01T00:00:00Z"},
+		map[string]interface{}{"id": "d", "title": "kiwi shoe", "created": "2024-04-01T00:00:00Z"},
+	)
+	tests := []struct {
+		name string
+		sort string
+		want []string
+	}{
+		{"price low to high, missing last", `[{"field":"price"}]`, []string{"b", "a", "c", "d"}},
+		{"missing first", `[{"field":"price","missing":"first"}]`, []string{"d", "b", "a", "c"}},
+		{"newest first", `[{"field":"created","order":"desc"}]`, []string{"d", "b", "c", "a"}},
+		{"title on the whole value", `[{"field":"title"}]`, []string{"b", "d", "c", "a"}},
+		{"tie broken by the next key", `[{"field":"price","order":"desc"},{"field":"created"}]`, []string{"a", "c", "b", "d"}},
+		{"id", `[{"field":"_id","order":"desc"}]`, []string{"d", "c", "b", "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := searchJSON(t, index, `{"query":{"match":{"field":"title","query":"shoe"}},"sort":`+tt.sort+`}`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hitIds(resp); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if _, err := searchJSON(t, index, `{"sort":[{"field":"colour"}]}`); err == nil {
+		t.Error("sorting on a field that is not searchable was accepted")
+	}
+}
+
+func TestDateHistogramKeepsEmptyBuckets(t *testing.T) {
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{{Field: "created", Type: models.DateTime}}},
+		map[string]interface{}{"id": "1", "created": "2024-01-10T00:00:00Z"},
+		map[string]interface{}{"id": "2", "created": "2024-03-10T00:00:00Z"},
+		map[string]interface{}{"id": "3", "created": "2024-03-20T00:00:00Z"},
+	)
+	resp, err := searchJSON(t, index, `{"facets":{"created":{"field":"created","date_histogram":{"interval":"month","start":"2024-01-01","end":"2024-04-01"}}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0)
+	for _, bucket := range resp["facets"].(search.FacetResults)["created"].DateRanges {
+		got = append(got, fmt.Sprintf("%s=%d", bucket.Name, bucket.Count))
+	}
+	want := []string{"2024-01-01T00:00:00Z=1", "2024-02-01T00:00:00Z=0", "2024-03-01T00:00:00Z=2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buckets = %v, want %v", got, want)
+	}
+}
+
+func TestCursorPaging(t *testing.T) {
+	docs := make([]map[string]interface{}, 0)
+	for n := 0; n < 7; n++ {
+		// scores differ by how often shoe appears, pairs of documents tie
+		docs = append(docs, map[string]interface{}{"id": fmt.Sprint(n), "title": "shoe" + strings.Repeat(" shoe", n/2) + strings.Repeat(" x", n), "price": float64(n % 3)})
+	}
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{
+		{Field: "title", Type: models.String}, {Field: "price", Type: models.Number}}}, docs...)
+	tests := []struct {
+		name string
+		sort string
+	}{
+		{"relevance", ``},
+		{"score then field", `,"sort":[{"field":"_score"},{"field":"price"}]`},
+		{"field then score", `,"sort":[{"field":"price"},{"field":"_score","order":"asc"}]`},
+		{"field", `,"sort":[{"field":"price","order":"desc"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := `{"query":{"match":{"field":"title","query":"shoe"}}` + tt.sort
+			all, err := searchJSON(t, index, query+`,"limit":100}`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := hitIds(all)
+			if len(want) != len(docs) {
+				t.Fatalf("found %d documents, want %d", len(want), len(docs))
+			}
+
+			got := make([]string, 0)
+			pages := make([]map[string]interface{}, 0)
+			cursor := ""
+			for page := 0; page < len(docs); page++ {
+				body := query + `,"limit":3}`
+				if cursor != "" {
+					body = query + `,"limit":3,"cursor":"` + cursor + `"}`
+				}
+				resp, err := searchJSON(t, index, body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids := hitIds(resp)
+				if len(ids) == 0 {
+					break
+				}
+				got = append(got, ids...)
+				pages = append(pages, resp)
+				cursor = resp["next_cursor"].(string)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("paged through %v, want %v", got, want)
+			}
+
+			back, err := searchJSON(t, index, query+`,"limit":3,"cursor":"`+pages[1]["prev_cursor"].(string)+`"}`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(hitIds(back), hitIds(pages[0])) {
+				t.Errorf("prev cursor of the second page gave %v, want %v", hitIds(back), hitIds(pages[0]))
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{
+		{Field: "title", Type: models.String, Autocomplete: true}, {Field: "brand", Type: models.String, Autocomplete: true},
+		{Field: "body", Type: models.String}}},
+		map[string]interface{}{"id": "1", "title": "Running Shoes", "brand": "Runner Co", "body": "runway"},
+		map[string]interface{}{"id": "2", "title": "running shoes", "brand": "Acme", "body": "runway"},
+		map[string]interface{}{"id": "3", "title": "Rain Jacket", "brand": "Acme", "body": "runway"},
+	)
+	suggestions := func(text, field string) []string {
+		t.Helper()
+		resp, err := index.Suggest(text, field, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, 0)
+		for _, suggestion := range resp["suggestions"].([]map[string]interface{}) {
+			// repeated values collapse into one suggestion whatever their case
+			got = append(got, suggestion["field"].(string)+":"+strings.ToLower(suggestion["text"].(string)))
+		}
+		sort.Strings(got)
+		return got
+	}
+	if got, want := suggestions("run", ""), []string{"brand:runner co", "title:running shoes"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestions for run = %v, want %v", got, want)
+	}
+	if got, want := suggestions("run sh", ""), []string{"title:running shoes"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestions for run sh = %v, want %v", got, want)
+	}
+	if got, want := suggestions("ac", "title"), []string{}; !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestions for ac on title = %v, want %v", got, want)
+	}
+	if _, err := index.Suggest("run", "body", 10); err != errors.ErrNoAutocomplete {
+		t.Errorf("suggest on a field without autocomplete: error = %v, want %v", err, errors.ErrNoAutocomplete)
+	}
+}
+
+func TestTypoTolerance(t *testing.T) {
+	docs := []map[string]interface{}{
+		{"id": "exact", "title": "keyboard", "sku": "keybord"},
+		{"id": "fuzzy", "title": "keybord", "sku": "kb-2"},
+		{"id": "far", "title": "kxybxrd", "sku": "kb-3"},
+	}
+	searchable := []models.IndexSearchable{{Field: "title", Type: models.String}, {Field: "sku", Type: models.String}}
+	plain := newTestIndex(t, models.IndexMapConfig{Index: "plain", Searchable: searchable}, docs...)
+	typos := newTestIndex(t, models.IndexMapConfig{Index: "typos", Searchable: searchable, TypoTolerance: &models.TypoTolerance{Enabled: true}}, docs...)
+	strict := newTestIndex(t, models.IndexMapConfig{Index: "strict", Searchable: searchable, TypoTolerance: &models.TypoTolerance{Enabled: true, DisableOnFields: []string{"title"}}}, docs...)
+
+	if got := hitIds(plain.Query("keyboard", 0, 10)); !reflect.DeepEqual(got, []string{"exact"}) {
+		t.Errorf("without typo tolerance found %v, want [exact]", got)
+	}
+	if got := hitIds(typos.Query("keyboard", 0, 10)); !reflect.DeepEqual(got, []string{"exact", "fuzzy"}) {
+		t.Errorf("with typo tolerance found %v, want the exact match ranked above the one typo match", got)
+	}
+	if got := hitIds(typos.Query("title:keyboard", 0, 10)); !reflect.DeepEqual(got, []string{"exact"}) {
+		t.Errorf("query string syntax found %v, want it left as written", got)
+	}
+	resp, err := searchJSON(t, strict, `{"query":{"match":{"field":"title","query":"keyboard"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hitIds(resp); !reflect.DeepEqual(got, []string{"exact"}) {
+		t.Errorf("match on a field with typos disabled found %v, want [exact]", got)
+	}
+	// keybords is one typo away from the title of fuzzy and from the sku of exact
+	if got := hitIds(strict.Query("keybords", 0, 10)); !reflect.DeepEqual(got, []string{"exact"}) {
+		t.Errorf("simple search with typos disabled on title found %v, want only the sku match", got)
+	}
+}
+
+func TestFieldAnalyzersInSimpleSearch(t *testing.T) {
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{
+		{Field: "title", Type: models.String}, {Field: "sku", Type: models.String, Analyzer: "keyword"},
+		{Field: "description", Type: models.String, Analyzer: "en"}}},
+		map[string]interface{}{"id": "1", "title": "trail", "sku": "XK200", "description": "running shoes"},
+		map[string]interface{}{"id": "2", "title": "road", "sku": "XK300", "description": "a jacket"},
+		map[string]interface{}{"id": "3", "title": "shoes", "sku": "XK400", "description": "a hat"},
+	)
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"XK200", []string{"1"}},
+		{"xk200", []string{}},
+		{"shoe", []string{"1"}},
+		{"shoes", []string{"1", "3"}},
+		{"ran", []string{}},
+		{"runs", []string{"1"}},
+		{"road XK400", []string{"2", "3"}},
+		{`"running shoe"`, []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := hitIds(index.Query(tt.query, 0, 10))
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("found %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSynonymsApplyAtQueryTime(t *testing.T) {
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{{Field: "title", Type: models.String}}},
+		map[string]interface{}{"id": "1", "title": "smart television"},
+		map[string]interface{}{"id": "2", "title": "tv stand"},
+		map[string]interface{}{"id": "3", "title": "radio"},
+	)
+	if got := hitIds(index.Query("tv", 0, 10)); !reflect.DeepEqual(got, []string{"2"}) {
+		t.Fatalf("before synonyms found %v, want [2]", got)
+	}
+	set := models.SynonymSet{Groups: [][]string{{"TV", "television"}}}
+	if err := internal.DB.PutMap(index.Name(), &set, internal.SynonymStore); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = internal.DB.Delete(index.Name(), internal.SynonymStore) })
+
+	got := hitIds(index.Query("tv", 0, 10))
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("simple search found %v, want [1 2]", got)
+	}
+	resp, err := searchJSON(t, index, `{"query":{"phrase":{"field":"title","query":"smart tv"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hitIds(resp); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("phrase found %v, want [1]", got)
+	}
+	count, err := index.Count(&models.QueryClause{QueryString: "television"})
+	if err != nil || count != 2 {
+		t.Errorf("count = %d, %v, want 2", count, err)
+	}
+}
+
+func TestDictionaryInSimpleSearch(t *testing.T) {
+	index := newTestIndex(t, models.IndexMapConfig{
+		Searchable: []models.IndexSearchable{{Field: "title", Type: models.String}},
+		Dictionary: &models.Dictionary{StopWords: []string{"pack"}, ProtectedWords: []string{"Running"}}},
+		map[string]interface{}{"id": "1", "title": "running shoes"},
+		map[string]interface{}{"id": "2", "title": "a pack of socks"},
+		map[string]interface{}{"id": "3", "title": "he runs"},
+	)
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"shoes", []string{"1"}},
+		{"shoe", []string{"1"}},
+		{"title:shoe", []string{"1"}},
+		{"sock", []string{"2"}},
+		{"pack", []string{}},
+		{"running", []string{"1"}},
+		{"run", []string{"3"}},
+		{"a", []string{"2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := hitIds(index.Query(tt.query, 0, 10))
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("found %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldBoosts(t *testing.T) {
+	docs := []map[string]interface{}{
+		{"id": "in-description", "title": "trail runner", "description": "waterproof leather boot"},
+		{"id": "in-title", "title": "leather boot", "description": "for the trail"},
+	}
+	searchable := []models.IndexSearchable{{Field: "title", Type: models.String, Boost: 5}, {Field: "description", Type: models.String}}
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: searchable}, docs...)
+
+	if got := hitIds(index.Query("leather boot", 0, 10)); !reflect.DeepEqual(got, []string{"in-title", "in-description"}) {
+		t.Errorf("simple search ranked %v, want the title match first", got)
+	}
+	tests := []struct {
+		name   string
+		fields string
+		want   []string
+	}{
+		{"configured boosts", ``, []string{"in-title", "in-description"}},
+		{"weights in the request", `,"fields":["title","description^20"]`, []string{"in-description", "in-title"}},
+		{"chosen field only", `,"fields":["description"]`, []string{"in-description"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := searchJSON(t, index, `{"query":{"multi_match":{"query":"leather boot"`+tt.fields+`}}}`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hitIds(resp); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ranked %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoring(t *testing.T) {
+	docs := make([]map[string]interface{}, 0)
+	for n := 0; n < 30; n++ {
+		// the text score falls as n grows, popularity rises with it
+		docs = append(docs, map[string]interface{}{"id": fmt.Sprintf("%02d", n), "title": "shoe" + strings.Repeat(" x", n), "popularity": float64(n)})
+	}
+	index := newTestIndex(t, models.IndexMapConfig{
+		Searchable: []models.IndexSearchable{{Field: "title", Type: models.String}, {Field: "popularity", Type: models.Number}},
+		Scoring:    &models.Scoring{Functions: []models.ScoringFunction{{Type: "static", Field: "popularity", Weight: 10}}, Window: 10}}, docs...)
+	query := `{"query":{"match":{"field":"title","query":"shoe"}}`
+
+	resp, err := searchJSON(t, index, query+`,"limit":3}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hitIds(resp); !reflect.DeepEqual(got, []string{"09", "08", "07"}) {
+		t.Errorf("rescored top = %v, want the most popular of the window first", got)
+	}
+	resp, err = searchJSON(t, index, query+`,"limit":3,"scoring":{"functions":[]}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hitIds(resp); !reflect.DeepEqual(got, []string{"00", "01", "02"}) {
+		t.Errorf("without scoring functions = %v, want text score order", got)
+	}
+
+	// pages crossing the window boundary neither repeat nor skip a hit
+	seen := make(map[string]bool)
+	for offset := 0; offset < len(docs); offset += 4 {
+		resp, err := searchJSON(t, index, fmt.Sprintf(`%s,"offset":%d,"limit":4}`, query, offset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, id := range hitIds(resp) {
+			if seen[id] {
+				t.Errorf("%s shown twice, page at offset %d", id, offset)
+			}
+			seen[id] = true
+		}
+	}
+	if len(seen) != len(docs) {
+		t.Errorf("paged through %d documents, want %d", len(seen), len(docs))
+	}
+}
+
+func TestMerchandisingRules(t *testing.T) {
+	docs := []map[string]interface{}{{"id": "other", "title": "sock"}}
+	for n := 0; n < 20; n++ {
+		// fewer repeats of shoe, lower score
+		docs = append(docs, map[string]interface{}{"id": fmt.Sprint(n), "title": strings.Repeat("shoe ", 20-n) + strings.Repeat("filler ", n)})
+	}
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{{Field: "title", Type: models.String}}}, docs...)
+	rules := func(rules ...models.MerchandisingRule) {
+		set := models.RuleSet{Rules: rules}
+		if err := internal.DB.PutMap(index.Name(), &set, internal.RuleStore); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() { _ = internal.DB.Delete(index.Name(), internal.RuleStore) })
+	top := func(query string) ([]string, interface{}) {
+		t.Helper()
+		resp := index.Query(query, 0, 4)
+		return hitIds(resp), resp["total"]
+	}
+
+	if got, total := top("shoe"); !reflect.DeepEqual(got, []string{"0", "1", "2", "3"}) || total != uint64(20) {
+		t.Fatalf("without rules = %v of %v", got, total)
+	}
+	rules(models.MerchandisingRule{Id: "boost", Pattern: "shoe", Boosts: []models.BoostedDoc{{Id: "15", Boost: 50}}})
+	if got, _ := top("shoe"); len(got) == 0 || got[0] != "15" {
+		t.Errorf("boosted 15 ranks %v, want it first", got)
+	}
+	rules(models.MerchandisingRule{Id: "pin", Pattern: "shoe", Pins: []models.PinnedDoc{{Id: "2", Position: 1}, {Id: "other", Position: 3}}, Hide: []string{"0"}})
+	if got, total := top("SHOE "); !reflect.DeepEqual(got, []string{"2", "1", "other", "3"}) || total != uint64(20) {
+		t.Errorf("pinned and hidden = %v of %v, want [2 1 other 3] of 20", got, total)
+	}
+	if got, _ := top("title:shoe"); !reflect.DeepEqual(got, []string{"0", "1", "2", "3"}) {
+		t.Errorf("an exact rule applied to another query: %v", got)
+	}
+	rules(models.MerchandisingRule{Id: "later", Pattern: "shoe", Hide: []string{"0"}, Start: time.Now().Add(time.Hour).Format(time.RFC3339)})
+	if got, _ := top("shoe"); !reflect.DeepEqual(got, []string{"0", "1", "2", "3"}) {
+		t.Errorf("a scheduled rule applied before its start: %v", got)
+	}
+}
+
+func TestGeo(t *testing.T) {
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{
+		{Field: "name", Type: models.String}, {Field: "loc", Type: models.GeoPoint, Lat: "latitude", Lon: "longitude"}}},
+		map[string]interface{}{"id": "amsterdam", "name": "amsterdam", "latitude": 52.37, "longitude": 4.90},
+		map[string]interface{}{"id": "utrecht", "name": "utrecht", "latitude": "52.09", "longitude": "5.12"},
+		map[string]interface{}{"id": "paris", "name": "paris", "latitude": 48.86, "longitude": 2.35},
+		map[string]interface{}{"id": "berlin", "name": "berlin", "latitude": 52.52, "longitude": 13.40},
+		map[string]interface{}{"id": "nowhere", "name": "nowhere", "latitude": "unknown", "longitude": 1})
+
+	tests := []struct {
+		name, query string
+		want        []string
+	}{
+		{"distance", `{"geo_distance":{"field":"loc","origin":{"lat":52.37,"lon":4.90},"distance":"50km"}}`, []string{"amsterdam", "utrecht"}},
+		{"bbox", `{"geo_bbox":{"field":"loc","top_left":{"lat":53,"lon":4},"bottom_right":{"lat":52,"lon":6}}}`, []string{"amsterdam", "utrecht"}},
+		{"polygon", `{"geo_polygon":{"field":"loc","points":[{"lat":49,"lon":2},{"lat":49,"lon":3},{"lat":48,"lon":3},{"lat":48,"lon":2}]}}`, []string{"paris"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := searchJSON(t, index, `{"query":`+tt.query+`}`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := hitIds(resp)
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hits = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// without a unit the distance is measured in meters
+	resp, err := searchJSON(t, index, `{"query":{"geo_distance":{"field":"loc","origin":{"lat":52.52,"lon":13.40},"distance":"1000km"}},
+		"sort":[{"field":"loc","origin":{"lat":52.52,"lon":13.40}}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hitIds(resp); !reflect.DeepEqual(got, []string{"berlin", "utrecht", "amsterdam", "paris"}) {
+		t.Errorf("nearest to berlin = %v", got)
+	}
+	for _, doc := range resp["data"].([]map[string]interface{}) {
+		if _, ok := doc["loc"]; !ok {
+			t.Errorf("%v lost its point", doc["_id"])
+		}
+		for field := range doc {
+			if strings.HasPrefix(field, "loc.") {
+				t.Errorf("%v returned the point as field %s", doc["_id"], field)
+			}
+		}
+	}
+}
+
+func TestJSONColumns(t *testing.T) {
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{
+		{Field: "attributes.color", Type: models.String}, {Field: "attributes.size", Type: models.Number}}},
+		map[string]interface{}{"id": "1", "attributes": `{"color": "red", "size": 42}`},
+		map[string]interface{}{"id": "2", "attributes": []byte(`{"color": "blue", "size": 40}`)})
+
+	resp, err := searchJSON(t, index, `{"query":{"match":{"field":"attributes.color","query":"red"}},
+		"facets":{"colors":{"field":"attributes.color"}}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hitIds(resp); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Fatalf("hits = %v, want [1]", got)
+	}
+	if terms := resp["facets"].(search.FacetResults)["colors"].Terms.Terms(); len(terms) != 1 || terms[0].Term != "red" {
+		t.Errorf("color facet = %v", terms)
+	}
+	hit := resp["data"].([]map[string]interface{})[0]
+	doc, err := index.Get("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"color": "red", "size": float64(42)}
+	if !reflect.DeepEqual(hit["attributes"], want) {
+		t.Errorf("hit attributes = %v, want %v", hit["attributes"], want)
+	}
+	if !reflect.DeepEqual(doc["attributes"], want) {
+		t.Errorf("stored attributes = %v, want %v", doc["attributes"], want)
+	}
+}
+
+func TestReindex(t *testing.T) {
+	docs := make([]map[string]interface{}, 0)
+	for n := 0; n < 1200; n++ {
+		docs = append(docs, map[string]interface{}{"id": fmt.Sprint(n), "title": "shoe", "tag": "Red Shoe"})
+	}
+	config := models.IndexMapConfig{Searchable: []models.IndexSearchable{{Field: "title", Type: models.String}}}
+	index := newTestIndex(t, config, docs...)
+	t.Cleanup(func() { _ = internal.DB.Delete(index.Name(), internal.IndexPathStore) })
+	tagged := func() uint64 {
+		t.Helper()
+		resp, err := searchJSON(t, index, `{"query":{"term":{"field":"tag","value":"Red Shoe"}}}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp["total"].(uint64)
+	}
+	wait := func() models.ReindexStatus {
+		t.Helper()
+		for deadline := time.Now().Add(30 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if status := index.ReindexStatus(); status.State != models.ReindexRunning {
+				return status
+			}
+		}
+		t.Fatal("reindex did not finish")
+		return models.ReindexStatus{}
+	}
+	if got := tagged(); got != 0 {
+		t.Fatalf("%d tags matched as keywords before the reindex", got)
+	}
+
+	config.Searchable = append(config.Searchable, models.IndexSearchable{Field: "tag", Type: models.Keyword})
+	config.Index, config.UniqueId = index.Name(), "id"
+	mapper, err := IndexMapping(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a failing commit keeps the index where it was
+	oldPath := index.Path()
+	if err := index.StartReindex(mapper, func() error { return fmt.Errorf("disk full") }); err != nil {
+		t.Fatal(err)
+	}
+	if status := wait(); status.State != models.ReindexFailed {
+		t.Fatalf("state = %v, want failed", status.State)
+	}
+	if dir := indexDir(index.Name()); index.Path() != oldPath || dir != oldPath {
+		t.Fatalf("failed reindex moved the index to %s, recorded %s", index.Path(), dir)
+	}
+	if orphans, _ := filepath.Glob(oldPath + ".*"); len(orphans) > 0 {
+		t.Errorf("failed reindex left %v behind", orphans)
+	}
+
+	if err := index.StartReindex(mapper, nil); err != nil {
+		t.Fatal(err)
+	}
+	// writes during the rebuild land on both indexes
+	if err := index.Index("new", map[string]interface{}{"title": "boot", "tag": "Red Shoe"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Delete("0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Index("1", map[string]interface{}{"title": "sandal", "tag": "Blue Sandal"}); err != nil {
+		t.Fatal(err)
+	}
+	if status := wait(); status.State != models.ReindexCompleted {
+		t.Fatalf("state = %v: %s", status.State, status.Error)
+	}
+
+	if index.Path() == oldPath || indexDir(index.Name()) != index.Path() {
+		t.Errorf("index runs from %s, recorded %s", index.Path(), indexDir(index.Name()))
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("old index %s was kept", oldPath)
+	}
+	if got := tagged(); got != 1199 {
+		t.Errorf("tags matched as keywords = %d, want 1199", got)
+	}
+	for id, want := range map[string]interface{}{"new": "boot", "1": "sandal", "2": "shoe"} {
+		doc, err := index.Get(id)
+		if err != nil || doc["title"] != want {
+			t.Errorf("doc %s = %v, %v, want title %v", id, doc, err, want)
+		}
+	}
+	if _, err := index.Get("0"); err == nil {
+		t.Error("a document deleted during the rebuild came back")
+	}
+}
+
+func TestOrphanedReindexDirsAreRemoved(t *testing.T) {
+	base := util.IndexPath("orphans")
+	current, stale, stranger, named := base+".2", base+".1", base+".backup", base+".3"
+	for _, dir := range []string{base, current, stale, stranger, named} {
+		dir := dir
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	}
+	// an index of its own that is named like a rebuild
+	if err := internal.DB.PutMap("orphans.3", &models.IndexMapConfig{Index: "orphans.3"}, internal.IndexConfigStore); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = internal.DB.Delete("orphans.3", internal.IndexConfigStore) })
+
+	removeOrphanedDirs("orphans", current, log.AppLog)
+	for dir, kept := range map[string]bool{base: false, stale: false, current: true, stranger: true, named: true} {
+		if _, err := os.Stat(dir); (err == nil) != kept {
+			t.Errorf("%s kept = %v, want %v", dir, err == nil, kept)
+		}
+	}
+}
+
+func TestPaginationMetadata(t *testing.T) {
+	docs := make([]map[string]interface{}, 0)
+	for n := 0; n < 25; n++ {
+		docs = append(docs, map[string]interface{}{"id": fmt.Sprintf("%02d", n), "title": "shoe"})
+	}
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{{Field: "title", Type: models.String}}}, docs...)
+
+	tests := []struct {
+		name           string
+		offset, limit  int
+		hits, wantSize int
+		hasMore        bool
+	}{
+		{"default limit", 0, 0, 10, 10, true},
+		{"middle page", 10, 10, 10, 10, true},
+		{"last page", 20, 10, 5, 10, false},
+		{"past the end", 30, 10, 0, 10, false},
+		{"limit is capped", 0, 5000, 25, 1000, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := searchJSON(t, index, fmt.Sprintf(`{"query":{"match":{"field":"title","query":"shoe"}},"offset":%d,"limit":%d}`, tt.offset, tt.limit))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp["total"] != uint64(25) || resp["hits"] != tt.hits || resp["limit"] != tt.wantSize ||
+				resp["offset"] != tt.offset || resp["has_more"] != tt.hasMore || len(hitIds(resp)) != tt.hits {
+				t.Errorf("total %v hits %v limit %v offset %v has_more %v", resp["total"], resp["hits"], resp["limit"], resp["offset"], resp["has_more"])
+			}
+		})
+	}
+}
+
+func TestHitsKeepRankingOrder(t *testing.T) {
+	docs := make([]map[string]interface{}, 0)
+	for n := 0; n < 12; n++ {
+		docs = append(docs, map[string]interface{}{"id": fmt.Sprint(n), "title": strings.Repeat("shoe ", 12-n) + strings.Repeat("filler ", n), "price": float64(n)})
+	}
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{
+		{Field: "title", Type: models.String}, {Field: "price", Type: models.Number}}}, docs...)
+
+	resp := index.Query("shoe", 0, 12)
+	if got, want := hitIds(resp), []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("hits = %v, want %v", got, want)
+	}
+	last := math.Inf(1)
+	for n, doc := range resp["data"].([]map[string]interface{}) {
+		score, _ := doc["_score"].(float64)
+		if score <= 0 || score > last {
+			t.Errorf("hit %d scored %v after %v", n, score, last)
+		}
+		last = score
+		if doc["price"] != float64(n) || doc["title"] == nil {
+			t.Errorf("hit %d came without its stored fields: %v", n, doc)
+		}
+	}
+}
+
+func TestKeywordIPAndArrayFields(t *testing.T) {
+	index := newTestIndex(t, models.IndexMapConfig{Searchable: []models.IndexSearchable{
+		{Field: "sku", Type: models.Keyword}, {Field: "client", Type: models.IP},
+		{Field: "tags", Type: models.TextArray}, {Field: "sizes", Type: models.NumberArray}}},
+		map[string]interface{}{"id": "1", "sku": "KB-3 Pro", "client": "10.0.0.7", "tags": `["red", "wide fit"]`, "sizes": "41,42"},
+		map[string]interface{}{"id": "2", "sku": "kb-3 pro", "client": "192.168.1.20", "tags": "blue", "sizes": []interface{}{44.0}})
+
+	tests := []struct {
+		name, query string
+		want        []string
+	}{
+		{"keyword matches whole and case sensitive", `{"term":{"field":"sku","value":"KB-3 Pro"}}`, []string{"1"}},
+		{"keyword is not tokenized", `{"term":{"field":"sku","value":"pro"}}`, []string{}},
+		{"ip within a network", `{"ip_range":{"field":"client","cidr":"10.0.0.0/8"}}`, []string{"1"}},
+		{"any value of a JSON array", `{"match":{"field":"tags","query":"wide"}}`, []string{"1"}},
+		{"a single value", `{"match":{"field":"tags","query":"blue"}}`, []string{"2"}},
+		{"any value of a comma separated list", `{"range":{"field":"sizes","gte":42,"lt":43}}`, []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := searchJSON(t, index, `{"query":`+tt.query+`}`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hitIds(resp); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hits = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	doc, err := index.Get("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"sku": "KB-3 Pro", "client": "10.0.0.7", "tags": []interface{}{"red", "wide fit"}, "sizes": []interface{}{41.0, 42.0}}
+	for field, value := range want {
+		if !reflect.DeepEqual(doc[field], value) {
+			t.Errorf("stored %s = %#v, want %#v", field, doc[field], value)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"Scout.go/errors"
+	"Scout.go/internal"
+	"Scout.go/log"
+	"Scout.go/models"
+	"Scout.go/util"
+	"fmt"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/index/scorch"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reindexPageSize is how many documents are copied while writes are held back
+const reindexPageSize = 500
+
+// StartReindex builds the index again with the mapping next to the live one. Writes keep landing on both
+// while the stored documents are copied, then the new index replaces the old one. commit persists what
+// the mapping was built from, it runs right before the swap and a failing commit abandons the rebuild.
+func (i *Index) StartReindex(mapper *mapping.IndexMappingImpl, commit func() error) error {
+	i.reindexMu.Lock()
+	if i.reindex.State == models.ReindexRunning {
+		i.reindexMu.Unlock()
+		return errors.ErrReindexRunning
+	}
+	started := time.Now()
+	i.reindex = models.ReindexStatus{Index: i.name, State: models.ReindexRunning, StartedAt: &started}
+	cancel, rebuilt := make(chan struct{}), make(chan struct{})
+	i.cancel, i.rebuilt = cancel, rebuilt
+	i.reindexMu.Unlock()
+
+	dir := fmt.Sprintf("%s.%d", util.IndexPath(i.name), started.UnixNano())
+	mirror, err := bleve.NewUsing(dir, mapper, scorch.Name, scorch.Name, nil)
+	if err != nil {
+		i.logger.Error(errors.ErrCreateIndex.Error(), zap.String("dir", dir), zap.Error(err))
+		i.finishReindex(err)
+		close(rebuilt)
+		return err
+	}
+
+	i.mu.Lock()
+	i.mirror = mirror
+	total, _ := i.index.DocCount()
+	i.mu.Unlock()
+
+	i.reindexMu.Lock()
+	i.reindex.Total = total
+	i.reindexMu.Unlock()
+
+	go func() {
+		defer close(rebuilt)
+		i.rebuild(mirror, mapper, dir, commit, cancel)
+	}()
+	return nil
+}
+
+// StopReindex cancels a running reindex and waits until the rebuild let go of the index and its directory.
+func (i *Index) StopReindex() {
+	i.reindexMu.Lock()
+	cancel, rebuilt := i.cancel, i.rebuilt
+	i.cancel = nil
+	i.reindexMu.Unlock()
+
+	if cancel != nil {
+		close(cancel)
+	}
+	if rebuilt != nil {
+		<-rebuilt
+	}
+}
+
+func cancelled(cancel chan struct{}) bool {
+	select {
+	case <-cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+func (i *Index) rebuild(mirror bleve.Index, mapper *mapping.IndexMappingImpl, dir string, commit func() error, cancel chan struct{}) {
+	// abandon runs with the write lock held and drops the half built index
+	abandon := func(err error) {
+		i.mirror = nil
+		i.mu.Unlock()
+		_ = mirror.Close()
+		_ = os.RemoveAll(dir)
+		i.finishReindex(err)
+	}
+	if err := i.copyInto(mirror, cancel); err != nil {
+		i.mu.Lock()
+		abandon(err)
+		return
+	}
+
+	i.mu.Lock()
+	if cancelled(cancel) {
+		abandon(errors.ErrReindexCancelled)
+		return
+	}
+	// the new directory is recorded before anything else, until then a restart keeps opening the old one
+	if err := internal.DB.Put(i.name, dir, internal.IndexPathStore); err != nil {
+		i.logger.Error("error putting index path", zap.String("dir", dir), zap.Error(err))
+		abandon(err)
+		return
+	}
+	old, oldPath := i.index, i.indexPath
+	if commit != nil {
+		if err := commit(); err != nil {
+			if err := internal.DB.Put(i.name, oldPath, internal.IndexPathStore); err != nil {
+				i.logger.Error("error putting index path", zap.String("dir", oldPath), zap.Error(err))
+			}
+			abandon(err)
+			return
+		}
+	}
+	if last, err := i.index.GetInternal(lastIndexedKey); err == nil && last != nil {
+		_ = mirror.SetInternal(lastIndexedKey, last)
+	}
+	i.index, i.indexPath, i.indexMapping = mirror, dir, mapper
+	i.mirror = nil
+	i.mu.Unlock()
+
+	if err := old.Close(); err != nil {
+		i.logger.Error(errors.ErrCloseIndex.Error(), zap.String("dir", oldPath), zap.Error(err))
+	}
+	if err := os.RemoveAll(oldPath); err != nil {
+		i.logger.Error("error removing old index", zap.String("dir", oldPath), zap.Error(err))
+	}
+	i.finishReindex(nil)
+}
+
+// copyInto pages through the live index in id order. Each page is copied while writes wait, so a
+// document is either copied or mirrored with its latest value.
+func (i *Index) copyInto(mirror bleve.Index, cancel chan struct{}) error {
+	var after []string
+	for {
+		if cancelled(cancel) {
+			return errors.ErrReindexCancelled
+		}
+		req := bleve.NewSearchRequestOptions(bleve.NewMatchAllQuery(), reindexPageSize, 0, false)
+		req.SortBy([]string{"_id"})
+		req.SearchAfter = after
+
+		i.mu.RLock()
+		res, err := i.index.Search(req)
+		i.mu.RUnlock()
+		if err != nil {
+			return err
+		}
+		if len(res.Hits) == 0 {
+			return nil
+		}
+
+		copied, err := i.copyPage(mirror, res)
+		if err != nil {
+			return err
+		}
+		i.reindexMu.Lock()
+		i.reindex.Copied += copied
+		i.reindexMu.Unlock()
+
+		after = []string{res.Hits[len(res.Hits)-1].ID}
+	}
+}
+
+func (i *Index) copyPage(mirror bleve.Index, res *bleve.SearchResult) (uint64, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	batch := mirror.NewBatch()
+	var copied uint64
+	for _, hit := range res.Hits {
+		doc, err := i.index.Document(hit.ID)
+		if err != nil {
+			return 0, err
+		}
+		if doc == nil {
+			// deleted since the page was read
+			continue
+		}
+		if err := batch.Index(hit.ID, nestFields(storedFields(doc))); err != nil {
+			i.logger.Error(errors.ErrIndexBatch.Error(), zap.String("id", hit.ID), zap.Error(err))
+			continue
+		}
+		if version, err := i.index.GetInternal(versionKey(hit.ID)); err == nil && version != nil {
+			batch.SetInternal(versionKey(hit.ID), version)
+		}
+		copied++
+	}
+	if err := mirror.Batch(batch); err != nil {
+		return 0, err
+	}
+	return copied, nil
+}
+
+// removeOrphanedDirs deletes the directories a reindex left behind, a rebuild that died before its swap
+// or an old index that outlived it. dir is where the index lives and is kept.
+func removeOrphanedDirs(name, dir string, logger *log.BaseLog) {
+	base := util.IndexPath(name)
+	candidates, _ := filepath.Glob(base + ".*")
+	if dir != base {
+		candidates = append(candidates, base)
+	}
+	for _, candidate := range candidates {
+		if candidate == dir {
+			continue
+		}
+		if nanos := strings.TrimPrefix(candidate, base+"."); nanos != candidate {
+			if _, err := strconv.ParseInt(nanos, 10, 64); err != nil {
+				continue
+			}
+			if _, err := internal.DB.Get(filepath.Base(candidate), internal.IndexConfigStore); err == nil {
+				// an index of its own that happens to be named like a rebuild
+				continue
+			}
+		}
+		if _, err := os.Stat(candidate); err != nil {
+			continue
+		}
+		if err := os.RemoveAll(candidate); err != nil {
+			logger.E(name, "error removing orphaned index", zap.String("dir", candidate), zap.Error(err))
+			continue
+		}
+		logger.I(name, "removed orphaned index", zap.String("dir", candidate))
+	}
+}
+
+func (i *Index) finishReindex(err error) {
+	finished := time.Now()
+
+	i.reindexMu.Lock()
+	defer i.reindexMu.Unlock()
+
+	i.reindex.FinishedAt = &finished
+	i.reindex.State = models.ReindexCompleted
+	if err != nil {
+		i.reindex.State = models.ReindexFailed
+		i.reindex.Error = err.Error()
+		i.logger.Error("reindex failed", zap.String("index", i.name), zap.Error(err))
+	}
+}
+
+// ReindexStatus reports the progress of the running or last reindex.
+func (i *Index) ReindexStatus() models.ReindexStatus {
+	i.reindexMu.Lock()
+	defer i.reindexMu.Unlock()
+
+	return i.reindex
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"Scout.go/dsl"
+	"Scout.go/models"
+	"github.com/blevesearch/bleve/v2"
+	"time"
+)
+
+// Count returns how many documents match the clause, every document without one.
+func (i *Index) Count(clause *models.QueryClause) (uint64, error) {
+	if clause == nil && i.members == nil {
+		i.mu.RLock()
+		defer i.mu.RUnlock()
+
+		return i.index.DocCount()
+	}
+	config, err := i.Config()
+	if err != nil {
+		return 0, err
+	}
+	q, err := dsl.NewQuery(clause, config)
+	if err != nil {
+		return 0, err
+	}
+	res, err := i.Search(bleve.NewSearchRequestOptions(i.expand(q, config), 0, 0, false))
+	if err != nil {
+		return 0, err
+	}
+	return res.Total, nil
+}
+
+// Statistics summarises the index from bleve's stats, a missing stat is left at zero.
+func (i *Index) Statistics() (models.IndexStatistics, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	count, err := i.index.DocCount()
+	if err != nil {
+		return models.IndexStatistics{}, err
+	}
+	res := models.IndexStatistics{Index: i.name, DocCount: count, Reindex: i.ReindexStatus().State}
+	if stats, ok := i.index.StatsMap()["index"].(map[string]interface{}); ok {
+		res.DiskSize = statValue(stats, "CurOnDiskBytes")
+		res.Segments = statValue(stats, "num_root_memorysegments") + statValue(stats, "num_root_filesegments")
+	}
+	if last, err := i.index.GetInternal(lastIndexedKey); err == nil && last != nil {
+		if at, err := time.Parse(time.RFC3339Nano, string(last)); err == nil {
+			res.LastIndexed = &at
+		}
+	}
+	return res, nil
+}
+
+func statValue(stats map[string]interface{}, key string) uint64 {
+	switch v := stats[key].(type) {
+	case uint64:
+		return v
+	case int:
+		return uint64(v)
+	case float64:
+		return uint64(v)
+	}
+	return 0
+}
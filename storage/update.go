@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"Scout.go/errors"
+	"Scout.go/util"
+	"encoding/binary"
+	"github.com/blevesearch/bleve/v2"
+	bleveindex "github.com/blevesearch/bleve_index_api"
+	"go.uber.org/zap"
+	"hash/fnv"
+	"sort"
+	"sync"
+)
+
+// idLockStripes bounds the per document locks, ids sharing a stripe simply wait for each other
+const idLockStripes = 256
+
+const versionKeyPrefix = "_scout_version:"
+
+type idLocks [idLockStripes]sync.Mutex
+
+// lock takes the stripes of the ids in ascending order so concurrent writers cannot deadlock.
+func (l *idLocks) lock(ids ...string) func() {
+	seen := make(map[uint32]bool, len(ids))
+	stripes := make([]int, 0, len(ids))
+	for _, id := range ids {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(id))
+		stripe := h.Sum32() % idLockStripes
+		if !seen[stripe] {
+			seen[stripe] = true
+			stripes = append(stripes, int(stripe))
+		}
+	}
+	sort.Ints(stripes)
+	for _, stripe := range stripes {
+		l[stripe].Lock()
+	}
+	return func() {
+		for _, stripe := range stripes {
+			l[stripe].Unlock()
+		}
+	}
+}
+
+func versionKey(id string) []byte {
+	return []byte(versionKeyPrefix + id)
+}
+
+func encodeVersion(version uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, version)
+	return b
+}
+
+func decodeVersion(b []byte) uint64 {
+	if len(b) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+// reader opens a snapshot of the live index. The caller holds the read lock and closes the reader.
+func (i *Index) reader() (bleveindex.IndexReader, error) {
+	advanced, err := i.index.Advanced()
+	if err != nil {
+		return nil, err
+	}
+	return advanced.Reader()
+}
+
+// versions reads the current version of every id from the snapshot, 0 for a document never written.
+func versions(reader bleveindex.IndexReader, ids []string) (map[string]uint64, error) {
+	res := make(map[string]uint64, len(ids))
+	for _, id := range ids {
+		if _, read := res[id]; read {
+			continue
+		}
+		b, err := reader.GetInternal(versionKey(id))
+		if err != nil {
+			return nil, err
+		}
+		res[id] = decodeVersion(b)
+	}
+	return res, nil
+}
+
+// bumpVersions adds the next version of every written id to the batches. The caller holds the read lock and
+// the id locks, and must not commit the batches when the versions could not be read.
+func (i *Index) bumpVersions(ids []string, batches ...*bleve.Batch) error {
+	reader, err := i.reader()
+	if err != nil {
+		i.logger.Error("error reading document versions", zap.String("index", i.name), zap.Error(err))
+		return err
+	}
+	defer reader.Close()
+
+	current, err := versions(reader, ids)
+	if err != nil {
+		i.logger.Error("error reading document versions", zap.String("index", i.name), zap.Error(err))
+		return err
+	}
+	for id, version := range current {
+		next := encodeVersion(version + 1)
+		for _, batch := range batches {
+			if batch != nil {
+				batch.SetInternal(versionKey(id), next)
+			}
+		}
+	}
+	return nil
+}
+
+// Version returns the current version of the document, 0 when it was written before versions were kept.
+func (i *Index) Version(id string) (uint64, error) {
+	if i.members != nil {
+		for _, member := range i.members {
+			if _, err := member.Get(id); err == nil {
+				return member.Version(id)
+			}
+		}
+		return 0, errors.ErrNotFound
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	reader, err := i.reader()
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	current, err := versions(reader, []string{id})
+	if err != nil {
+		return 0, err
+	}
+	return current[id], nil
+}
+
+// Update merges the changes into the stored document and indexes the result. A nil value removes the
+// field, nested objects are merged. With an expected version the update only applies to that version.
+func (i *Index) Update(id string, changes map[string]interface{}, expected *uint64) (uint64, map[string]interface{}, error) {
+	config, err := i.Config()
+	if err != nil {
+		return 0, nil, err
+	}
+	if v, ok := changes[config.UniqueId]; ok {
+		if uid, err := util.ToString(v); err != nil || uid != id {
+			return 0, nil, errors.ErrUniqueIdMismatch
+		}
+	}
+
+	unlock := i.ids.lock(id)
+	defer unlock()
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	// the document and its version come from the same snapshot
+	reader, err := i.reader()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer reader.Close()
+
+	doc, err := reader.Document(id)
+	if err != nil {
+		return 0, nil, err
+	}
+	if doc == nil {
+		return 0, nil, errors.ErrNotFound
+	}
+	current, err := versions(reader, []string{id})
+	if err != nil {
+		return 0, nil, err
+	}
+	version := current[id]
+	if expected != nil && *expected != version {
+		return version, nil, errors.ErrVersionConflict
+	}
+
+	expandJSONColumns(changes, config)
+	normalizeArrays(changes, config)
+	merged := mergeFields(nestFields(storedFields(doc)), changes)
+	if _, ok := merged[config.UniqueId]; !ok {
+		merged[config.UniqueId] = id
+	}
+	geoPoints(merged, config)
+
+	batch := i.index.NewBatch()
+	mirrored := i.mirrorBatch()
+	if err := batch.Index(id, merged); err != nil {
+		i.logger.Error(errors.ErrIndexDoc.Error(), zap.String("id", id), zap.Error(err))
+		return version, nil, err
+	}
+	if mirrored != nil {
+		if err := mirrored.Index(id, merged); err != nil {
+			i.logger.Error(errors.ErrIndexDoc.Error(), zap.String("id", id), zap.String("mirror", i.name), zap.Error(err))
+		}
+	}
+	next := encodeVersion(version + 1)
+	batch.SetInternal(versionKey(id), next)
+	if mirrored != nil {
+		mirrored.SetInternal(versionKey(id), next)
+	}
+	markIndexed(batch, mirrored)
+	if err := i.index.Batch(batch); err != nil {
+		i.logger.Error(errors.ErrIndexDoc.Error(), zap.String("id", id), zap.Error(err))
+		return version, nil, err
+	}
+	i.applyMirror(mirrored)
+
+	return version + 1, merged, nil
+}
+
+// mergeFields applies the changes onto the document the way a JSON merge patch does.
+func mergeFields(doc, changes map[string]interface{}) map[string]interface{} {
+	for k, v := range changes {
+		if v == nil {
+			delete(doc, k)
+			continue
+		}
+		if sub, ok := v.(map[string]interface{}); ok {
+			if existing, ok := doc[k].(map[string]interface{}); ok {
+				doc[k] = mergeFields(existing, sub)
+				continue
+			}
+		}
+		doc[k] = v
+	}
+	return doc
+}
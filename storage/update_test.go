@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"Scout.go/errors"
+	"Scout.go/models"
+	"reflect"
+	"testing"
+)
+
+func TestMergeFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     map[string]interface{}
+		changes map[string]interface{}
+		want    map[string]interface{}
+	}{
+		{
+			name:    "set and add",
+			doc:     map[string]interface{}{"title": "shoe", "price": 10.0},
+			changes: map[string]interface{}{"price": 12.0, "stock": 3.0},
+			want:    map[string]interface{}{"title": "shoe", "price": 12.0, "stock": 3.0},
+		},
+		{
+			name:    "null removes",
+			doc:     map[string]interface{}{"title": "shoe", "price": 10.0},
+			changes: map[string]interface{}{"price": nil, "missing": nil},
+			want:    map[string]interface{}{"title": "shoe"},
+		},
+		{
+			name:    "nested objects merge",
+			doc:     map[string]interface{}{"attributes": map[string]interface{}{"color": "red", "size": 9.0}},
+			changes: map[string]interface{}{"attributes": map[string]interface{}{"color": "blue", "size": nil}},
+			want:    map[string]interface{}{"attributes": map[string]interface{}{"color": "blue"}},
+		},
+		{
+			name:    "object replaces a scalar",
+			doc:     map[string]interface{}{"attributes": "none"},
+			changes: map[string]interface{}{"attributes": map[string]interface{}{"color": "red"}},
+			want:    map[string]interface{}{"attributes": map[string]interface{}{"color": "red"}},
+		},
+		{
+			name:    "arrays are replaced",
+			doc:     map[string]interface{}{"tags": []interface{}{"a", "b"}},
+			changes: map[string]interface{}{"tags": []interface{}{"c"}},
+			want:    map[string]interface{}{"tags": []interface{}{"c"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeFields(tt.doc, tt.changes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		b    []byte
+		want uint64
+	}{
+		{"never written", nil, 0},
+		{"round trip", encodeVersion(42), 42},
+		{"large", encodeVersion(1 << 40), 1 << 40},
+		{"wrong length", []byte{1, 2, 3}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeVersion(tt.b); got != tt.want {
+				t.Errorf("decodeVersion() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// newProductIndex holds one product keyed by sku, written once so it is at version 1.
+func newProductIndex(t *testing.T) *Index {
+	return newTestIndex(t, models.IndexMapConfig{UniqueId: "sku", Searchable: []models.IndexSearchable{
+		{Field: "title", Type: models.String}, {Field: "price", Type: models.Number}, {Field: "attributes.color", Type: models.String}}},
+		map[string]interface{}{"sku": "p1", "title": "red shoe", "price": 10.0, "attributes": map[string]interface{}{"color": "red", "size": 9.0}})
+}
+
+func TestUpdateMergesFields(t *testing.T) {
+	index := newProductIndex(t)
+
+	version, doc, err := index.Update("p1", map[string]interface{}{"price": nil, "attributes": map[string]interface{}{"color": "blue"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"sku": "p1", "title": "red shoe", "attributes": map[string]interface{}{"color": "blue", "size": 9.0}}
+	if version != 2 || !reflect.DeepEqual(doc, want) {
+		t.Errorf("Update() = %d, %v, want 2, %v", version, doc, want)
+	}
+	if stored, err := index.Get("p1"); err != nil || !reflect.DeepEqual(stored, want) {
+		t.Errorf("Get() = %v, %v, want %v", stored, err, want)
+	}
+	// the merged document is indexed again, not only stored
+	for query, hits := range map[string][]string{
+		`{"match":{"field":"attributes.color","query":"blue"}}`: {"p1"},
+		`{"match":{"field":"attributes.color","query":"red"}}`:  {},
+		`{"range":{"field":"price","gte":0}}`:                   {},
+	} {
+		resp, err := searchJSON(t, index, `{"query":`+query+`}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hitIds(resp); !reflect.DeepEqual(got, hits) {
+			t.Errorf("%s = %v, want %v", query, got, hits)
+		}
+	}
+}
+
+func TestUpdateWithExpectedVersion(t *testing.T) {
+	index := newProductIndex(t)
+	expect := func(v uint64) *uint64 { return &v }
+
+	if version, _, err := index.Update("p1", map[string]interface{}{"price": 12.0}, expect(1)); err != nil || version != 2 {
+		t.Fatalf("Update() at the current version = %d, %v", version, err)
+	}
+	// a writer that read version 1 lost the race
+	version, _, err := index.Update("p1", map[string]interface{}{"price": 1.0}, expect(1))
+	if err != errors.ErrVersionConflict || version != 2 {
+		t.Errorf("Update() at a stale version = %d, %v, want 2, %v", version, err, errors.ErrVersionConflict)
+	}
+	if doc, _ := index.Get("p1"); doc["price"] != 12.0 {
+		t.Errorf("a rejected update changed the price to %v", doc["price"])
+	}
+}
+
+func TestUpdateRejects(t *testing.T) {
+	index := newProductIndex(t)
+
+	if _, _, err := index.Update("p1", map[string]interface{}{"sku": "p2"}, nil); err != errors.ErrUniqueIdMismatch {
+		t.Errorf("changing the unique id gave %v, want %v", err, errors.ErrUniqueIdMismatch)
+	}
+	if _, _, err := index.Update("nope", map[string]interface{}{"price": 1.0}, nil); err != errors.ErrNotFound {
+		t.Errorf("updating a missing document gave %v, want %v", err, errors.ErrNotFound)
+	}
+	if version, _ := index.Version("p1"); version != 1 {
+		t.Errorf("rejected updates moved the version to %d", version)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"github.com/goccy/go-json"
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -122,6 +124,47 @@ func ToString(value any) (string, error) {
 	}
 }
 
+func ToFloat(value any) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	case []byte:
+		return strconv.ParseFloat(strings.TrimSpace(string(v)), 64)
+	default:
+		s, err := ToString(v)
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseFloat(s, 64)
+	}
+}
+
+// ParseJSON decodes a JSON object or array held as text, anything else is reported as not JSON.
+func ParseJSON(value any) (interface{}, bool) {
+	var raw []byte
+	switch v := value.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return nil, false
+	}
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || (raw[0] != '{' && raw[0] != '[') {
+		return nil, false
+	}
+	var parsed interface{}
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, false
+	}
+	return parsed, true
+}
+
 func MakeUniqueById(arr []map[string]interface{}) []map[string]interface{} {
 	uniqueMap := make(map[string]map[string]interface{})
 	for _, item := range arr {